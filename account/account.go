@@ -4,6 +4,8 @@ import (
 	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/skip-mev/chaintestutil/sample"
 )
 
 // Account is an abstraction around a cosmos-sdk private-key (account)
@@ -11,10 +13,26 @@ type Account struct {
 	pk cryptotypes.PrivKey
 }
 
-// NewAccount returns a new account, with a randomly generated private-key.
+// NewAccount returns a new account, with a private-key derived from sample.Rand, so that the accounts are
+// reproducible with the seed of the sample package.
 func NewAccount() *Account {
+	secret := make([]byte, 32)
+	_, _ = sample.Rand().Read(secret)
+
+	return NewAccountFromSecret(secret)
+}
+
+// NewAccountFromSecret returns a new account, with a private-key deterministically derived from the secret.
+func NewAccountFromSecret(secret []byte) *Account {
+	return &Account{
+		pk: secp256k1.GenPrivKeyFromSecret(secret),
+	}
+}
+
+// NewAccountFromPrivKey returns an account for the given private-key.
+func NewAccountFromPrivKey(pk cryptotypes.PrivKey) *Account {
 	return &Account{
-		pk: secp256k1.GenPrivKey(),
+		pk: pk,
 	}
 }
 
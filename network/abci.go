@@ -0,0 +1,244 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// WithConsensusParams returns a ConfigOption that mutates the consensus params of the genesis with fn. The
+// params are initialized to the CometBFT defaults if the config does not set them.
+func WithConsensusParams(fn func(params *cmttypes.ConsensusParams)) ConfigOption {
+	return func(cfg *Config) error {
+		if cfg.ConsensusParams == nil {
+			cfg.ConsensusParams = cmttypes.DefaultConsensusParams()
+		}
+
+		fn(cfg.ConsensusParams)
+		return nil
+	}
+}
+
+// WithVoteExtensions returns a ConfigOption enabling vote extensions from the given height. The app must set
+// its ExtendVote and VerifyVoteExtension handlers, e.g. with WithBaseAppOptions, or the chain halts at that
+// height.
+func WithVoteExtensions(enableHeight int64) ConfigOption {
+	return func(cfg *Config) error {
+		if enableHeight <= 0 {
+			return errors.New("vote extensions enable height must be positive")
+		}
+
+		return WithConsensusParams(func(params *cmttypes.ConsensusParams) {
+			params.ABCI.VoteExtensionsEnableHeight = enableHeight
+		})(cfg)
+	}
+}
+
+// WithABCIRecorder returns a ConfigOption recording the ABCI++ calls handled by the app of every node into
+// recorder, see ABCIRecorder.
+func WithABCIRecorder(recorder *ABCIRecorder) ConfigOption {
+	return func(cfg *Config) error {
+		cfg.ABCIRecorder = recorder
+		return nil
+	}
+}
+
+// ExtendVoteCall is a vote extension built by the app of a node.
+type ExtendVoteCall struct {
+	// Node is the moniker of the node.
+	Node          string
+	Height        int64
+	VoteExtension []byte
+	Err           error
+}
+
+// VerifyVoteExtensionCall is the verification by the app of a node of the vote extension of a validator.
+type VerifyVoteExtensionCall struct {
+	// Node is the moniker of the node.
+	Node      string
+	Height    int64
+	Validator sdk.ConsAddress
+	// VoteExtension is the verified extension.
+	VoteExtension []byte
+	Accepted      bool
+	Err           error
+}
+
+// PrepareProposalCall is a proposal built by the app of a node.
+type PrepareProposalCall struct {
+	// Node is the moniker of the node.
+	Node   string
+	Height int64
+	// Txs are the txs of the proposal returned by the app.
+	Txs [][]byte
+	Err error
+}
+
+// ProcessProposalCall is the verification by the app of a node of a proposal.
+type ProcessProposalCall struct {
+	// Node is the moniker of the node.
+	Node     string
+	Height   int64
+	Proposer sdk.ConsAddress
+	Txs      [][]byte
+	Accepted bool
+	Err      error
+}
+
+// ABCIRecorder records the ABCI++ calls handled by the apps of the nodes, to assert on the vote extensions and
+// proposals of the network. It is set with WithABCIRecorder, and its zero value is ready to use. Only the calls
+// made by CometBFT are recorded, whatever the app constructor.
+type ABCIRecorder struct {
+	mu                   sync.Mutex
+	extendVotes          []ExtendVoteCall
+	verifyVoteExtensions []VerifyVoteExtensionCall
+	prepareProposals     []PrepareProposalCall
+	processProposals     []ProcessProposalCall
+}
+
+// ExtendVotes returns the vote extensions built by the nodes at the given height.
+func (r *ABCIRecorder) ExtendVotes(height int64) []ExtendVoteCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return callsAt(r.extendVotes, func(c ExtendVoteCall) bool { return c.Height == height })
+}
+
+// VerifyVoteExtensions returns the verifications of vote extensions made by the nodes at the given height.
+func (r *ABCIRecorder) VerifyVoteExtensions(height int64) []VerifyVoteExtensionCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return callsAt(r.verifyVoteExtensions, func(c VerifyVoteExtensionCall) bool { return c.Height == height })
+}
+
+// PreparedProposals returns the proposals built by the nodes at the given height, one per round in which the
+// node proposed.
+func (r *ABCIRecorder) PreparedProposals(height int64) []PrepareProposalCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return callsAt(r.prepareProposals, func(c PrepareProposalCall) bool { return c.Height == height })
+}
+
+// ProcessedProposals returns the verifications of proposals made by the nodes at the given height.
+func (r *ABCIRecorder) ProcessedProposals(height int64) []ProcessProposalCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return callsAt(r.processProposals, func(c ProcessProposalCall) bool { return c.Height == height })
+}
+
+// RejectedProposals returns the verifications of proposals that rejected the proposal at the given height.
+func (r *ABCIRecorder) RejectedProposals(height int64) []ProcessProposalCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return callsAt(r.processProposals, func(c ProcessProposalCall) bool { return c.Height == height && !c.Accepted })
+}
+
+// wrap returns the ABCI application of the node recording its calls.
+func (r *ABCIRecorder) wrap(node string, app abci.Application) abci.Application {
+	return recordingApp{Application: app, node: node, recorder: r}
+}
+
+// callsAt returns a copy of the calls matching the filter.
+func callsAt[T any](calls []T, filter func(T) bool) []T {
+	var matching []T
+	for _, call := range calls {
+		if filter(call) {
+			matching = append(matching, call)
+		}
+	}
+
+	return matching
+}
+
+// recordingApp is the ABCI application of a node recording its ABCI++ calls.
+type recordingApp struct {
+	abci.Application
+
+	node     string
+	recorder *ABCIRecorder
+}
+
+func (a recordingApp) ExtendVote(ctx context.Context, req *abci.RequestExtendVote) (*abci.ResponseExtendVote, error) {
+	resp, err := a.Application.ExtendVote(ctx, req)
+
+	call := ExtendVoteCall{Node: a.node, Height: req.Height, Err: err}
+	if resp != nil {
+		call.VoteExtension = resp.VoteExtension
+	}
+
+	a.recorder.mu.Lock()
+	a.recorder.extendVotes = append(a.recorder.extendVotes, call)
+	a.recorder.mu.Unlock()
+
+	return resp, err
+}
+
+func (a recordingApp) VerifyVoteExtension(
+	ctx context.Context,
+	req *abci.RequestVerifyVoteExtension,
+) (*abci.ResponseVerifyVoteExtension, error) {
+	resp, err := a.Application.VerifyVoteExtension(ctx, req)
+
+	call := VerifyVoteExtensionCall{
+		Node:          a.node,
+		Height:        req.Height,
+		Validator:     req.ValidatorAddress,
+		VoteExtension: req.VoteExtension,
+		Accepted:      resp != nil && resp.IsAccepted(),
+		Err:           err,
+	}
+
+	a.recorder.mu.Lock()
+	a.recorder.verifyVoteExtensions = append(a.recorder.verifyVoteExtensions, call)
+	a.recorder.mu.Unlock()
+
+	return resp, err
+}
+
+func (a recordingApp) PrepareProposal(
+	ctx context.Context,
+	req *abci.RequestPrepareProposal,
+) (*abci.ResponsePrepareProposal, error) {
+	resp, err := a.Application.PrepareProposal(ctx, req)
+
+	call := PrepareProposalCall{Node: a.node, Height: req.Height, Err: err}
+	if resp != nil {
+		call.Txs = resp.Txs
+	}
+
+	a.recorder.mu.Lock()
+	a.recorder.prepareProposals = append(a.recorder.prepareProposals, call)
+	a.recorder.mu.Unlock()
+
+	return resp, err
+}
+
+func (a recordingApp) ProcessProposal(
+	ctx context.Context,
+	req *abci.RequestProcessProposal,
+) (*abci.ResponseProcessProposal, error) {
+	resp, err := a.Application.ProcessProposal(ctx, req)
+
+	call := ProcessProposalCall{
+		Node:     a.node,
+		Height:   req.Height,
+		Proposer: req.ProposerAddress,
+		Txs:      req.Txs,
+		Accepted: resp != nil && resp.IsAccepted(),
+		Err:      err,
+	}
+
+	a.recorder.mu.Lock()
+	a.recorder.processProposals = append(a.recorder.processProposals, call)
+	a.recorder.mu.Unlock()
+
+	return resp, err
+}
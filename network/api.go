@@ -0,0 +1,136 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// APIError is the error returned by the gRPC-gateway of a node when a request fails.
+type APIError struct {
+	StatusCode int
+	// Body is the body of the response, a JSON object holding the gRPC code and message of the error if the
+	// request reached a route.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// APIGet performs a GET request to the path of the gRPC-gateway of the targeted node, e.g.
+// "/cosmos/bank/v1beta1/params", and decodes the JSON response into resp with the network's codec. The state at
+// the height of the suite is queried. An *APIError is returned if the response status is not 200.
+func (s *TestSuite) APIGet(ctx context.Context, path string, resp proto.Message) error {
+	status, body, err := s.apiGet(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return &APIError{StatusCode: status, Body: string(body)}
+	}
+
+	if err := s.Network.Config.Codec.UnmarshalJSON(body, resp); err != nil {
+		return fmt.Errorf("decoding response of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// apiGet performs a GET request to the path of the gRPC-gateway of the targeted node and returns the status and
+// the body of the response.
+func (s *TestSuite) apiGet(ctx context.Context, path string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Validator().APIAddress+path, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if s.height != 0 {
+		req.Header.Set(grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(s.height, 10))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+// RequireAPIMatchesGRPC fails the test if the response of the gRPC-gateway route at path differs from the response
+// of the gRPC query for req, both being queried at the same height from the targeted node. The gRPC response is
+// returned.
+//
+//	network.RequireAPIMatchesGRPC(t, s, "/cosmos/bank/v1beta1/params",
+//		banktypes.NewQueryClient(cc).Params, &banktypes.QueryParamsRequest{})
+func RequireAPIMatchesGRPC[Req, Resp proto.Message](
+	t testing.TB,
+	s *TestSuite,
+	path string,
+	query func(context.Context, Req, ...grpc.CallOption) (Resp, error),
+	req Req,
+) Resp {
+	t.Helper()
+
+	// pin the height, so that both responses are for the same state. The height is the last one committed by the
+	// targeted node, as the rest of the network may be ahead of it.
+	if s.height == 0 {
+		height, err := s.committedHeight()
+		require.NoError(t, err)
+		s = s.StateAt(height)
+	}
+
+	grpcResp, err := query(s.queryContext(), req)
+	require.NoError(t, err, "gRPC query for %s", path)
+
+	// proto.Clone cannot copy responses holding custom types, such as the amounts of coins
+	apiResp := reflect.New(reflect.TypeOf(grpcResp).Elem()).Interface().(proto.Message)
+	require.NoError(t, s.APIGet(context.Background(), path, apiResp))
+
+	cdc := s.Network.Config.Codec
+	grpcJSON, err := cdc.MarshalJSON(grpcResp)
+	require.NoError(t, err)
+	apiJSON, err := cdc.MarshalJSON(apiResp)
+	require.NoError(t, err)
+
+	require.JSONEq(t, string(grpcJSON), string(apiJSON), "API response of %s differs from the gRPC response", path)
+
+	return grpcResp
+}
+
+// RequireAPIRoute fails the test if no gRPC-gateway route is registered for GET requests to path on the targeted
+// node, e.g. because the module did not register its gateway routes in RegisterGRPCGatewayRoutes. The request
+// may fail once routed, e.g. if the path references a missing resource.
+func (s *TestSuite) RequireAPIRoute(t testing.TB, path string) {
+	t.Helper()
+
+	status, body, err := s.apiGet(context.Background(), path)
+	require.NoError(t, err)
+
+	// the gateway answers requests matching no route with a 501 and a fixed message, which differs from the
+	// message of the Unimplemented errors returned by gRPC handlers
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	unrouted := status == http.StatusNotImplemented &&
+		json.Unmarshal(body, &apiErr) == nil && apiErr.Message == http.StatusText(http.StatusNotImplemented)
+
+	require.False(t, unrouted, "no API route registered for GET %s", path)
+}
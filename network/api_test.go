@@ -0,0 +1,91 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"testing"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestAPI(t *testing.T) {
+	ctx := context.Background()
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000))
+
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 2
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(1, coins))
+	acc := s.Accounts[0]
+
+	for _, node := range s.Nodes() {
+		node.RequireAPIRoute(t, "/cosmos/bank/v1beta1/params")
+
+		cc, err := node.GRPC()
+		require.NoError(t, err)
+		network.RequireAPIMatchesGRPC(t, node, "/cosmos/bank/v1beta1/balances/"+acc.Address().String(),
+			banktypes.NewQueryClient(cc).AllBalances, &banktypes.QueryAllBalancesRequest{Address: acc.Address().String()})
+
+		var resp banktypes.QueryAllBalancesResponse
+		require.NoError(t, node.APIGet(ctx, "/cosmos/bank/v1beta1/balances/"+acc.Address().String(), &resp))
+		require.Equal(t, coins, resp.Balances)
+	}
+
+	var apiErr *network.APIError
+	err := s.APIGet(ctx, "/cosmos/bank/v1beta1/unknown", &banktypes.QueryParamsResponse{})
+	require.True(t, errors.As(err, &apiErr), "unexpected error %v", err)
+	require.Equal(t, http.StatusNotImplemented, apiErr.StatusCode)
+}
+
+func TestRequireAPIRoute(t *testing.T) {
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	s := network.NewSuite(t, cfg)
+
+	// a routed request failing on a missing resource passes
+	rt := recordFailures(t, func(t testing.TB) { s.RequireAPIRoute(t, "/cosmos/bank/v1beta1/denoms_metadata/unknown") })
+	require.False(t, rt.failed, rt.errors)
+
+	rt = recordFailures(t, func(t testing.TB) { s.RequireAPIRoute(t, "/cosmos/bank/v1beta1/unknown") })
+	require.True(t, rt.failed)
+	require.Len(t, rt.errors, 1)
+	require.Contains(t, rt.errors[0], "no API route registered for GET /cosmos/bank/v1beta1/unknown")
+}
+
+// recordingT records the failures of a test instead of failing it.
+type recordingT struct {
+	testing.TB
+	failed bool
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.failed = true
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) FailNow() {
+	r.failed = true
+	runtime.Goexit()
+}
+
+// recordFailures runs fn with a recordingT wrapping t and returns it once fn returned or failed the test.
+func recordFailures(t testing.TB, fn func(t testing.TB)) *recordingT {
+	rt := &recordingT{TB: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(rt)
+	}()
+	<-done
+
+	return rt
+}
@@ -0,0 +1,118 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	cmttypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Block is a committed block of the network along with the results of its execution.
+type Block struct {
+	Height int64
+	Hash   cmtbytes.HexBytes
+	Time   time.Time
+	// Proposer is the consensus address of the validator that proposed the block.
+	Proposer sdk.ConsAddress
+	// AppHash is the app hash resulting from the execution of the block.
+	AppHash []byte
+	// Txs are the txs of the block, in the order they were included by the proposer.
+	Txs []BlockTx
+	// Events are the events emitted by the block outside of txs, e.g. by begin and end blockers.
+	Events []abci.Event
+	// ValidatorUpdates are the validator set updates returned by the block.
+	ValidatorUpdates []abci.ValidatorUpdate
+
+	// Raw is the block as returned by CometBFT.
+	Raw *cmttypes.Block
+}
+
+// BlockTx is a tx included in a block, decoded with the network's TxConfig, along with its execution result.
+type BlockTx struct {
+	Bytes []byte
+	Hash  cmtbytes.HexBytes
+	// Tx is the decoded tx. It is nil if the tx could not be decoded, e.g. if it was injected by the proposer
+	// in PrepareProposal, in which case DecodeErr is set.
+	Tx        sdk.Tx
+	DecodeErr error
+	// Result is the result of the execution of the tx.
+	Result *abci.ExecTxResult
+}
+
+// Msgs returns the messages of the tx, or nil if it could not be decoded.
+func (tx BlockTx) Msgs() []sdk.Msg {
+	if tx.Tx == nil {
+		return nil
+	}
+
+	return tx.Tx.GetMsgs()
+}
+
+// Block returns the block at the given height from the targeted node, along with the results of its execution.
+func (s *TestSuite) Block(ctx context.Context, height int64) (*Block, error) {
+	return s.block(ctx, &height)
+}
+
+// LatestBlock returns the latest block committed by the targeted node, along with the results of its execution.
+func (s *TestSuite) LatestBlock(ctx context.Context) (*Block, error) {
+	return s.block(ctx, nil)
+}
+
+func (s *TestSuite) block(ctx context.Context, height *int64) (*Block, error) {
+	cometClient, err := s.GetCometClient()
+	if err != nil {
+		return nil, err
+	}
+
+	blockResp, err := cometClient.Block(ctx, height)
+	if err != nil {
+		return nil, err
+	}
+
+	// the block results RPC omits the app hash, so the results are loaded from the state store of the node
+	stateStore, err := s.Validator().stateStore()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := stateStore.LoadFinalizeBlockResponse(blockResp.Block.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results.TxResults) != len(blockResp.Block.Txs) {
+		return nil, fmt.Errorf("block %d has %d txs but %d tx results",
+			blockResp.Block.Height, len(blockResp.Block.Txs), len(results.TxResults))
+	}
+
+	txDecoder := s.Validator().ClientCtx.TxConfig.TxDecoder()
+
+	txs := make([]BlockTx, len(blockResp.Block.Txs))
+	for i, bz := range blockResp.Block.Txs {
+		tx, err := txDecoder(bz)
+
+		txs[i] = BlockTx{
+			Bytes:     bz,
+			Hash:      bz.Hash(),
+			Tx:        tx,
+			DecodeErr: err,
+			Result:    results.TxResults[i],
+		}
+	}
+
+	return &Block{
+		Height:           blockResp.Block.Height,
+		Hash:             blockResp.BlockID.Hash,
+		Time:             blockResp.Block.Time,
+		Proposer:         sdk.ConsAddress(blockResp.Block.ProposerAddress),
+		AppHash:          results.AppHash,
+		Txs:              txs,
+		Events:           results.Events,
+		ValidatorUpdates: results.ValidatorUpdates,
+		Raw:              blockResp.Block,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestBlockAppHash(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 2
+	s := network.NewSuite(t, cfg)
+
+	_, err := s.Network.WaitForHeightWithTimeout(3, time.Minute)
+	require.NoError(t, err)
+
+	block, err := s.Block(ctx, 2)
+	require.NoError(t, err)
+	require.NotEmpty(t, block.AppHash)
+
+	// the app hash of a block is committed to by the header of the next block
+	next, err := s.Block(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, []byte(next.Raw.AppHash), block.AppHash)
+
+	for i, node := range s.Nodes() {
+		nodeBlock, err := node.Block(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, block.AppHash, nodeBlock.AppHash, "app hash of validator %d differs", i)
+	}
+}
+
+func TestBlockTxs(t *testing.T) {
+	ctx := context.Background()
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000))
+	amount := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 100))
+
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(2, coins))
+	sender, recipient := s.Accounts[0], s.Accounts[1]
+
+	send := banktypes.NewMsgSend(sender.Address(), recipient.Address(), amount)
+	bz, err := s.CreateTxBytes(ctx, network.TxGenInfo{
+		Account:  sender,
+		GasLimit: 200_000,
+		Fee:      sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10)),
+	}, send)
+	require.NoError(t, err)
+	res, err := s.BroadcastTxCommit(ctx, bz)
+	require.NoError(t, err)
+	txRes, err := s.DecodeBroadcastTxCommit(res)
+	require.NoError(t, err)
+	require.NoError(t, txRes.Err())
+
+	block, err := s.Block(ctx, txRes.Height)
+	require.NoError(t, err)
+	require.Len(t, block.Txs, 1)
+
+	tx := block.Txs[0]
+	require.NoError(t, tx.DecodeErr)
+	require.Equal(t, res.Hash, tx.Hash)
+	require.Len(t, tx.Msgs(), 1)
+	require.Equal(t, send, tx.Msgs()[0])
+	require.Equal(t, uint32(0), tx.Result.Code)
+
+	// the fee is also transferred, to the fee collector, so the transfer to the recipient is looked up
+	var transferred string
+	for _, event := range tx.Result.Events {
+		if event.Type != banktypes.EventTypeTransfer {
+			continue
+		}
+		attrs := make(map[string]string)
+		for _, attr := range event.Attributes {
+			attrs[attr.Key] = attr.Value
+		}
+		if attrs[banktypes.AttributeKeyRecipient] == recipient.Address().String() {
+			require.Equal(t, sender.Address().String(), attrs[banktypes.AttributeKeySender])
+			transferred = attrs[sdk.AttributeKeyAmount]
+		}
+	}
+	require.Equal(t, amount.String(), transferred)
+}
@@ -0,0 +1,171 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/gogoproto/proto"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// cliTxGas is the gas limit of the txs sent by RunCLI if no gas flag is given.
+	cliTxGas = flags.DefaultGasLimit
+	// cliTxTimeout is how long RunCLI waits for the tx sent by a tx command to be included.
+	cliTxTimeout = 30 * time.Second
+)
+
+// CLIOutput is the output of a command run with RunCLI.
+type CLIOutput struct {
+	Stdout string
+	Stderr string
+	// TxResult is the result of the tx sent by a tx command, once included in a block. It is nil for query
+	// commands.
+	TxResult *TxResult
+
+	cdc codec.Codec
+}
+
+// Unmarshal decodes the JSON output of the command into resp with the network's codec.
+func (o *CLIOutput) Unmarshal(resp proto.Message) error {
+	return o.cdc.UnmarshalJSON([]byte(o.Stdout), resp)
+}
+
+// RunCLI runs the cobra command of a module with the client context of the targeted validator, which holds its
+// keyring, its node and the chain ID, and captures its output. The output of query commands is formatted as JSON,
+// so that it can be decoded with CLIOutput.Unmarshal, and they query the state at the height of the suite.
+//
+// Tx commands, which are identified by their --from flag, are signed by the targeted validator's account unless
+// --from is given, pay the minimum fee unless --fees or --gas-prices is given, and are confirmed with --yes. RunCLI
+// then waits for the tx to be included and sets its result in the output. An error is only returned if the
+// command failed or the tx could not be broadcast; a tx failing CheckTx or its execution is reported in its
+// result.
+//
+//	out, err := s.RunCLI(bankcli.NewSendTxCmd(cdc.InterfaceRegistry().SigningContext().AddressCodec()),
+//		val.Address.String(), addr.String(), "10stake")
+func (s *TestSuite) RunCLI(cmd *cobra.Command, args ...string) (*CLIOutput, error) {
+	val := s.Validator()
+
+	isTx := cmd.Flags().Lookup(flags.FlagFrom) != nil
+	if isTx {
+		txArgs, err := s.cliTxArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, txArgs...)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.SetArgs(args)
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+
+	clientCtx := val.ClientCtx.
+		WithOutput(&stdout).
+		WithOutputFormat(flags.OutputFormatJSON).
+		WithHeight(s.height)
+
+	ctx := context.WithValue(context.Background(), client.ClientContextKey, &clientCtx)
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		return nil, fmt.Errorf("running %s: %w: %s", cmd.Name(), err, stderr.String())
+	}
+
+	out := &CLIOutput{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+		cdc:    s.Network.Config.Codec,
+	}
+
+	if isTx {
+		var err error
+		if out.TxResult, err = s.cliTxResult(out.Stdout); err != nil {
+			return out, err
+		}
+	}
+
+	return out, nil
+}
+
+// cliTxArgs returns the flags completing the args of a tx command.
+func (s *TestSuite) cliTxArgs(args []string) ([]string, error) {
+	var txArgs []string
+	if !hasFlag(args, flags.FlagFrom) {
+		txArgs = append(txArgs, fmt.Sprintf("--%s=%s", flags.FlagFrom, s.Validator().Moniker))
+	}
+
+	if !hasFlag(args, flags.FlagFees) && !hasFlag(args, flags.FlagGasPrices) {
+		gas := uint64(cliTxGas)
+		for i, arg := range args {
+			value, ok := strings.CutPrefix(arg, "--"+flags.FlagGas+"=")
+			if !ok && arg == "--"+flags.FlagGas && i+1 < len(args) {
+				value, ok = args[i+1], true
+			}
+
+			if ok {
+				var err error
+				if gas, err = strconv.ParseUint(value, 10, 64); err != nil {
+					return nil, fmt.Errorf("gas must be a fixed limit to compute the fee: %w", err)
+				}
+			}
+		}
+
+		fee, err := s.minFee(gas)
+		if err != nil {
+			return nil, err
+		}
+		txArgs = append(txArgs, fmt.Sprintf("--%s=%s", flags.FlagFees, fee))
+	}
+
+	return append(txArgs,
+		fmt.Sprintf("--%s", flags.FlagSkipConfirmation),
+		fmt.Sprintf("--%s=%s", flags.FlagBroadcastMode, flags.BroadcastSync),
+	), nil
+}
+
+// cliTxResult returns the result of the tx whose broadcast response was printed by a tx command, waiting for the
+// tx to be included if it passed CheckTx.
+func (s *TestSuite) cliTxResult(stdout string) (*TxResult, error) {
+	var resp struct {
+		TxHash    string `json:"txhash"`
+		Code      uint32 `json:"code"`
+		Codespace string `json:"codespace"`
+		RawLog    string `json:"raw_log"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &resp); err != nil {
+		return nil, fmt.Errorf("decoding broadcast response: %w", err)
+	}
+
+	hash, err := hex.DecodeString(resp.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("decoding tx hash: %w", err)
+	}
+
+	if resp.Code != 0 {
+		return &TxResult{Hash: hash, Code: resp.Code, Codespace: resp.Codespace, Log: resp.RawLog}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cliTxTimeout)
+	defer cancel()
+
+	return s.WaitForTx(ctx, hash)
+}
+
+// hasFlag returns whether the flag is set in args.
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--"+name || strings.HasPrefix(arg, "--"+name+"=") {
+			return true
+		}
+	}
+
+	return false
+}
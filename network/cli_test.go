@@ -0,0 +1,55 @@
+package network_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	authcli "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+	bankcli "github.com/cosmos/cosmos-sdk/x/bank/client/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/account"
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestRunCLI(t *testing.T) {
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 2
+	s := network.NewSuite(t, cfg)
+	addressCodec := cfg.TxConfig.SigningContext().AddressCodec()
+	recipient := account.NewAccount()
+	amount := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10))
+
+	for i, node := range s.Nodes() {
+		// the tx is signed by the validator of the node
+		out, err := node.RunCLI(bankcli.NewSendTxCmd(addressCodec),
+			node.Validator().Address.String(), recipient.Address().String(), amount.String())
+		require.NoError(t, err)
+		require.NotNil(t, out.TxResult)
+		require.NoError(t, out.TxResult.Err(), "tx of validator %d", i)
+
+		// query commands print JSON
+		out, err = node.RunCLI(authcli.QueryTxCmd(), hex.EncodeToString(out.TxResult.Hash))
+		require.NoError(t, err)
+		require.Nil(t, out.TxResult)
+		var txResp sdk.TxResponse
+		require.NoError(t, out.Unmarshal(&txResp))
+		require.Zero(t, txResp.Code)
+	}
+
+	// the first validator may not have committed the block including the tx of the last one yet
+	require.NoError(t, s.Network.WaitForNextBlock())
+	balances, err := s.Balances(*recipient)
+	require.NoError(t, err)
+	require.Equal(t, amount.MulInt(sdkmath.NewInt(int64(cfg.NumValidators))), balances)
+
+	// a tx failing its execution is reported in its result
+	out, err := s.RunCLI(bankcli.NewSendTxCmd(addressCodec),
+		s.Validator().Address.String(), recipient.Address().String(), "1000000000000000000stake")
+	require.NoError(t, err)
+	require.ErrorIs(t, out.TxResult.Err(), sdkerrors.ErrInsufficientFunds)
+}
@@ -0,0 +1,96 @@
+package network
+
+import (
+	"sync"
+
+	cmthttp "github.com/cometbft/cometbft/rpc/client/http"
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// clientCache holds the clients of each node of the network. The clients are created lazily and shared
+// by all views of a TestSuite, so that connections are reused across queries and txs.
+type clientCache struct {
+	mtx   sync.Mutex
+	grpc  map[string]*grpc.ClientConn
+	comet map[string]*cmthttp.HTTP
+}
+
+func newClientCache() *clientCache {
+	return &clientCache{
+		grpc:  make(map[string]*grpc.ClientConn),
+		comet: make(map[string]*cmthttp.HTTP),
+	}
+}
+
+// grpcConn returns the gRPC connection to the given address, dialing it on first use. Messages are encoded with
+// the codec of the SDK, which handles the custom types of the responses, e.g. decimals, and unpacks their Anys
+// with the interface registry.
+func (c *clientCache) grpcConn(addr string, registry codectypes.InterfaceRegistry) (*grpc.ClientConn, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if cc, ok := c.grpc[addr]; ok {
+		return cc, nil
+	}
+
+	cc, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(codec.NewProtoCodec(registry).GRPCCodec())),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.grpc[addr] = cc
+	return cc, nil
+}
+
+// cometClient returns the CometBFT RPC client for the given address, creating it on first use.
+func (c *clientCache) cometClient(addr string) (*cmthttp.HTTP, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if client, ok := c.comet[addr]; ok {
+		return client, nil
+	}
+
+	client, err := cmthttp.New(addr, "/websocket")
+	if err != nil {
+		return nil, err
+	}
+
+	c.comet[addr] = client
+	return client, nil
+}
+
+// resetBackoff makes the gRPC connection to the given address, if any, reconnect immediately instead of
+// waiting for its backoff to expire, e.g. once the node it targets has been restarted.
+func (c *clientCache) resetBackoff(addr string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if cc, ok := c.grpc[addr]; ok {
+		cc.ResetConnectBackoff()
+	}
+}
+
+// close closes all the clients of the cache.
+func (c *clientCache) close() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for addr, cc := range c.grpc {
+		_ = cc.Close()
+		delete(c.grpc, addr)
+	}
+
+	for addr, client := range c.comet {
+		if client.IsRunning() {
+			_ = client.Stop()
+		}
+		delete(c.comet, addr)
+	}
+}
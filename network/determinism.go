@@ -0,0 +1,153 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+
+	sm "github.com/cometbft/cometbft/state"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/stretchr/testify/require"
+)
+
+// NodeHashes are the hashes computed by the app of a node when executing a block.
+type NodeHashes struct {
+	// Node is the moniker of the node.
+	Node    string
+	AppHash []byte
+	// ResultsHash is the hash of the results of the txs of the block, set as LastResultsHash in the header of
+	// the next block.
+	ResultsHash []byte
+	// StoreHashes are the commit hashes of the stores of the app by store name, nil if the app does not expose
+	// its multistore.
+	StoreHashes map[string][]byte
+}
+
+// DivergenceError is returned by CheckDeterminism when the validators computed different hashes for a block.
+type DivergenceError struct {
+	Height int64
+	Hashes []NodeHashes
+}
+
+// Error reports the hashes of every node, with the stores whose commit hash differs between nodes marked by a
+// star, so that the module whose state diverged can be identified.
+func (e *DivergenceError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "validators diverged at height %d:", e.Height)
+
+	var stores []string
+	for store := range e.Hashes[0].StoreHashes {
+		stores = append(stores, store)
+	}
+	sort.Strings(stores)
+
+	for _, hashes := range e.Hashes {
+		fmt.Fprintf(&b, "\n%s: app hash %X, results hash %X", hashes.Node, hashes.AppHash, hashes.ResultsHash)
+		if hashes.StoreHashes == nil {
+			b.WriteString("\n  store hashes unavailable")
+			continue
+		}
+
+		for _, store := range stores {
+			mark := " "
+			for _, other := range e.Hashes {
+				if other.StoreHashes != nil && !bytes.Equal(other.StoreHashes[store], hashes.StoreHashes[store]) {
+					mark = "*"
+				}
+			}
+			fmt.Fprintf(&b, "\n %s %s: %X", mark, store, hashes.StoreHashes[store])
+		}
+	}
+
+	return b.String()
+}
+
+// CheckDeterminism compares the app hash and the hash of the tx results computed by every running validator for
+// each block from fromHeight up to the latest block committed by all of them. The blocks are checked from the
+// earliest block kept by the validators if fromHeight is zero. A *DivergenceError reporting the hashes of every
+// store of each validator is returned for the first block the validators disagree on. As a validator computing
+// a different app hash cannot commit the following blocks, the diverging block is the last one it committed.
+func (s *TestSuite) CheckDeterminism(ctx context.Context, fromHeight int64) error {
+	var (
+		vals     []*Validator
+		stores   []sm.Store
+		toHeight int64 = math.MaxInt64
+	)
+	for _, v := range s.Network.Validators {
+		if !v.IsRunning() {
+			continue
+		}
+
+		status, err := v.RPCClient.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		// the block results RPC omits the app hash, which is read from the state store of the node
+		store, err := v.stateStore()
+		if err != nil {
+			return err
+		}
+
+		vals = append(vals, v)
+		stores = append(stores, store)
+		fromHeight = max(fromHeight, status.SyncInfo.EarliestBlockHeight)
+		toHeight = min(toHeight, status.SyncInfo.LatestBlockHeight)
+	}
+
+	if len(vals) == 0 {
+		return errors.New("no running validators")
+	}
+
+	for height := fromHeight; height <= toHeight; height++ {
+		hashes := make([]NodeHashes, len(vals))
+		diverged := false
+		for i, v := range vals {
+			res, err := stores[i].LoadFinalizeBlockResponse(height)
+			if err != nil {
+				return fmt.Errorf("loading results of block %d from %s: %w", height, v.Moniker, err)
+			}
+
+			hashes[i] = NodeHashes{
+				Node:        v.Moniker,
+				AppHash:     res.AppHash,
+				ResultsHash: cmttypes.NewResults(res.TxResults).Hash(),
+			}
+
+			diverged = diverged ||
+				!bytes.Equal(hashes[i].AppHash, hashes[0].AppHash) ||
+				!bytes.Equal(hashes[i].ResultsHash, hashes[0].ResultsHash)
+		}
+
+		if !diverged {
+			continue
+		}
+
+		for i, v := range vals {
+			// the commit info is unavailable for apps not exposing their multistore
+			if info, err := commitInfo(v, height); err == nil {
+				hashes[i].StoreHashes = make(map[string][]byte)
+				for _, store := range info.StoreInfos {
+					hashes[i].StoreHashes[store.Name] = store.CommitId.Hash
+				}
+			}
+		}
+
+		return &DivergenceError{Height: height, Hashes: hashes}
+	}
+
+	return nil
+}
+
+// RequireDeterminism fails the test if the validators computed different hashes for a block, see
+// CheckDeterminism.
+func (s *TestSuite) RequireDeterminism(t testing.TB, fromHeight int64) {
+	t.Helper()
+
+	require.NoError(t, s.CheckDeterminism(context.Background(), fromHeight))
+}
@@ -0,0 +1,27 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestCheckDeterminism(t *testing.T) {
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 3
+	s := network.NewSuite(t, cfg)
+
+	_, err := s.Network.WaitForHeightWithTimeout(4, time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, s.CheckDeterminism(context.Background(), 0))
+
+	// a stopped validator is not compared
+	require.NoError(t, s.Node(2).StopNode())
+	s.RequireDeterminism(t, 2)
+}
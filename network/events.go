@@ -0,0 +1,221 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtpubsub "github.com/cometbft/cometbft/libs/pubsub"
+	cmtquery "github.com/cometbft/cometbft/libs/pubsub/query"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+)
+
+// subscriberPrefix prefixes the subscriber IDs of the subscriptions made to the event bus of a node. Each
+// subscription uses its own ID, as the event bus rejects a second subscription of a subscriber to a query.
+const subscriberPrefix = "chaintestutil"
+
+// expectEventCapacity is the capacity of the subscriptions made by ExpectEvent. Only the first event is used,
+// but it is kept when later events overflow the subscription.
+const expectEventCapacity = 1
+
+// subscriptions counts the subscriptions made to derive unique subscriber IDs.
+var subscriptions atomic.Uint64
+
+// Event is an event received from a subscription to the events of a node.
+type Event struct {
+	// Query is the query the event was received for.
+	Query string
+	// Block is set if the event is a NewBlock event.
+	Block *cmttypes.EventDataNewBlock
+	// Tx is set if the event is a Tx event.
+	Tx *cmttypes.EventDataTx
+	// DecodedTx is the tx of a Tx event, decoded with the network's TxConfig. It is nil if the event is not
+	// a Tx event or the tx could not be decoded.
+	DecodedTx sdk.Tx
+	// Events are the ABCI events emitted by the block or the tx.
+	Events []abci.Event
+	// TypedEvents are the events that were emitted as typed SDK events, decoded into their proto message.
+	TypedEvents []proto.Message
+
+	// Raw is the event as received from CometBFT.
+	Raw coretypes.ResultEvent
+}
+
+// Subscription is a subscription to the events of a node.
+type Subscription struct {
+	events chan Event
+	err    error
+}
+
+// Events returns the channel of the received events. It is closed once the subscription is cancelled.
+func (sub *Subscription) Events() <-chan Event {
+	return sub.events
+}
+
+// Err returns why the subscription was cancelled once the channel of events is closed: nil if its context
+// is done, or an error if events were dropped because the subscriber fell behind or the node was stopped.
+func (sub *Subscription) Err() error {
+	return sub.err
+}
+
+// Subscribe subscribes to the events of the targeted node matching the CometBFT query, e.g.
+// "tm.event='Tx' AND message.sender='cosmos1...'". The subscription is registered before Subscribe returns.
+// Up to outCapacity events are buffered until they are received. If the subscriber falls further behind, the
+// subscription is cancelled instead of dropping events silently, and Err reports it. The subscription is also
+// cancelled once ctx is done.
+func (s *TestSuite) Subscribe(ctx context.Context, query string, outCapacity int) (*Subscription, error) {
+	if outCapacity < 1 {
+		return nil, fmt.Errorf("the capacity of a subscription must be positive, got %d", outCapacity)
+	}
+
+	v := s.Validator()
+	if !v.IsRunning() {
+		return nil, fmt.Errorf("the node of %s is not running", v.Moniker)
+	}
+
+	q, err := cmtquery.New(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %w", query, err)
+	}
+
+	eventBus := v.tmNode.EventBus()
+	subscriber := fmt.Sprintf("%s-%d", subscriberPrefix, subscriptions.Add(1))
+	busSub, err := eventBus.Subscribe(ctx, subscriber, q, outCapacity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %q: %w", query, err)
+	}
+
+	sub := &Subscription{events: make(chan Event)}
+	go func() {
+		defer close(sub.events)
+		defer func() {
+			_ = eventBus.Unsubscribe(context.Background(), subscriber, q)
+		}()
+
+		forward := func(msg cmtpubsub.Message) bool {
+			event := coretypes.ResultEvent{Query: query, Data: msg.Data(), Events: msg.Events()}
+			select {
+			case sub.events <- s.decodeEvent(event):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-busSub.Out():
+				if !forward(msg) {
+					return
+				}
+			case <-busSub.Canceled():
+				// deliver the events buffered before the subscription was cancelled
+			drain:
+				for {
+					select {
+					case msg := <-busSub.Out():
+						if !forward(msg) {
+							return
+						}
+					default:
+						break drain
+					}
+				}
+
+				sub.err = busSub.Err()
+				if sub.err == nil {
+					sub.err = fmt.Errorf("the node of %s was stopped", v.Moniker)
+				}
+				sub.err = fmt.Errorf("subscription to %q cancelled: %w", query, sub.err)
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// ExpectEvent subscribes to the events of the targeted node matching the query and returns a wait func. wait
+// returns the first event received, or an error if no event was received within the timeout. Run the action
+// expected to emit the event between ExpectEvent and wait, so the event cannot fire before the subscription
+// is registered. The subscription is released once wait returns or ctx is done.
+func (s *TestSuite) ExpectEvent(
+	ctx context.Context,
+	query string,
+	timeout time.Duration,
+) (wait func() (Event, error), err error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	sub, err := s.Subscribe(ctx, query, expectEventCapacity)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return func() (Event, error) {
+		defer cancel()
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				if err := sub.Err(); err != nil {
+					return Event{}, err
+				}
+				return Event{}, ctx.Err()
+			}
+			return event, nil
+		case <-timer.C:
+			return Event{}, fmt.Errorf("no event received for query %q within %s", query, timeout)
+		case <-ctx.Done():
+			return Event{}, ctx.Err()
+		}
+	}, nil
+}
+
+// decodeEvent decodes the data of the event received from CometBFT.
+func (s *TestSuite) decodeEvent(event coretypes.ResultEvent) Event {
+	decoded := Event{
+		Query: event.Query,
+		Raw:   event,
+	}
+
+	switch data := event.Data.(type) {
+	case cmttypes.EventDataNewBlock:
+		decoded.Block = &data
+		decoded.Events = data.ResultFinalizeBlock.Events
+	case cmttypes.EventDataTx:
+		decoded.Tx = &data
+		decoded.Events = data.Result.Events
+
+		if tx, err := s.Validator().ClientCtx.TxConfig.TxDecoder()(data.Tx); err == nil {
+			decoded.DecodedTx = tx
+		}
+	}
+
+	decoded.TypedEvents = typedEvents(decoded.Events)
+
+	return decoded
+}
+
+// typedEvents returns the events that were emitted as typed SDK events, decoded into their proto message.
+func typedEvents(events []abci.Event) []proto.Message {
+	var typed []proto.Message
+	for _, event := range events {
+		// events that were not emitted as typed events do not resolve to a proto message
+		if typedEvent, err := sdk.ParseTypedEvent(event); err == nil {
+			typed = append(typed, typedEvent)
+		}
+	}
+
+	return typed
+}
@@ -0,0 +1,80 @@
+package network_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	cmtpubsub "github.com/cometbft/cometbft/libs/pubsub"
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestEvents(t *testing.T) {
+	ctx := context.Background()
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000))
+
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(2, coins))
+
+	t.Run("expect event", func(t *testing.T) {
+		sender, recipient := s.Accounts[0], s.Accounts[1]
+		query := fmt.Sprintf("tm.event='Tx' AND message.sender='%s'", sender.Address())
+
+		wait, err := s.ExpectEvent(ctx, query, time.Minute)
+		require.NoError(t, err)
+
+		msg := banktypes.NewMsgSend(sender.Address(), recipient.Address(), sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1)))
+		bz, err := s.CreateTxBytes(ctx, network.TxGenInfo{
+			Account:  sender,
+			GasLimit: 200_000,
+			Fee:      sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10)),
+		}, msg)
+		require.NoError(t, err)
+		requireTxSuccess(t, s, bz)
+
+		event, err := wait()
+		require.NoError(t, err)
+		require.NotNil(t, event.Tx)
+		require.NotNil(t, event.DecodedTx)
+		require.Equal(t, []sdk.Msg{msg}, event.DecodedTx.GetMsgs())
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		subCtx, cancel := context.WithCancel(ctx)
+		sub, err := s.Subscribe(subCtx, "tm.event='NewBlock'", 10)
+		require.NoError(t, err)
+
+		first := <-sub.Events()
+		second := <-sub.Events()
+		require.Equal(t, first.Block.Block.Height+1, second.Block.Block.Height)
+
+		cancel()
+		for range sub.Events() {
+		}
+		require.NoError(t, sub.Err())
+	})
+
+	t.Run("subscriber behind", func(t *testing.T) {
+		sub, err := s.Subscribe(ctx, "tm.event='NewBlock'", 1)
+		require.NoError(t, err)
+
+		// the events of two blocks are held for the subscriber, the third one overflows the subscription
+		height, err := s.Network.LatestHeight()
+		require.NoError(t, err)
+		_, err = s.Network.WaitForHeightWithTimeout(height+4, time.Minute)
+		require.NoError(t, err)
+
+		var received int
+		for range sub.Events() {
+			received++
+		}
+		require.NotZero(t, received)
+		require.ErrorIs(t, sub.Err(), cmtpubsub.ErrOutOfCapacity)
+	})
+}
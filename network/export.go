@@ -0,0 +1,452 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/cometbft/cometbft/privval"
+	cmtproto "github.com/cometbft/cometbft/proto/tendermint/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/runtime"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
+	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	slashingkeeper "github.com/cosmos/cosmos-sdk/x/slashing/keeper"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
+	"github.com/cosmos/cosmos-sdk/x/staking"
+	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+)
+
+// ExportableApp is implemented by apps that can export their state to a genesis, as SDK apps do for the export
+// command.
+type ExportableApp interface {
+	ExportAppStateAndValidators(forZeroHeight bool, jailAllowedAddrs, modulesToExport []string) (servertypes.ExportedApp, error)
+}
+
+// InvariantsApp is implemented by apps registering the invariants of their modules, e.g. with
+// module.Manager.RegisterInvariants.
+type InvariantsApp interface {
+	RegisterInvariants(ir sdk.InvariantRegistry)
+}
+
+// queryContextApp is implemented by apps embedding a BaseApp, which creates contexts on their committed state.
+type queryContextApp interface {
+	CreateQueryContext(height int64, prove bool) (sdk.Context, error)
+}
+
+// ExportOptions configures the export of the state of the network by ExportAndRestart.
+type ExportOptions struct {
+	// ForZeroHeight exports the state for a new chain starting at height 1, as done by the export command with
+	// --for-zero-height.
+	ForZeroHeight bool
+	// JailAllowedAddrs are the operator addresses of the validators left unjailed by a zero height export. All the
+	// validators are left unjailed if empty.
+	JailAllowedAddrs []string
+	// ModulesToExport are the modules whose state is exported. All the modules are exported if empty.
+	ModulesToExport []string
+}
+
+// ExportAndRestart stops the network and exports the state of the first validator with the
+// ExportAppStateAndValidators method of its app, then boots the validators and the full nodes on a fresh chain
+// from the exported genesis, keeping their keys. The invariants of the app are checked on the exported state and
+// once the new chain committed its first block, if the app implements InvariantsApp. The exported genesis is
+// returned.
+//
+// The app built by NewAppConstructor with WithOnDiskDB supports exports, including for zero height.
+func (s *TestSuite) ExportAndRestart(ctx context.Context, opts ExportOptions) (*genutiltypes.AppGenesis, error) {
+	nodes := append(s.Nodes(), s.FullNodes()...)
+	for _, node := range nodes {
+		if node.Validator().IsRunning() {
+			if err := node.StopNode(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	genesis, err := s.Network.exportGenesis(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, node := range nodes {
+		if err := s.Network.resetValidator(node.Validator(), genesis); err != nil {
+			return nil, err
+		}
+
+		if err := node.StartNode(); err != nil {
+			return nil, fmt.Errorf("starting node %d from the exported genesis: %w", i, err)
+		}
+	}
+
+	if err := s.waitForHeight(ctx, max(genesis.InitialHeight, 1)); err != nil {
+		return nil, err
+	}
+
+	if err := s.CheckInvariants(); err != nil {
+		return nil, fmt.Errorf("after restarting from the exported genesis: %w", err)
+	}
+
+	return genesis, nil
+}
+
+// CheckInvariants checks the invariants registered by the app of the targeted validator on its latest committed
+// state, if the app implements InvariantsApp. The broken invariants are returned as an error.
+func (s *TestSuite) CheckInvariants() error {
+	return checkInvariants(s.Validator().app, 0)
+}
+
+// checkInvariants checks the invariants of the app on its state at the given height, or at its latest height if
+// zero.
+func checkInvariants(app servertypes.Application, height int64) error {
+	invariantsApp, ok := app.(InvariantsApp)
+	if !ok {
+		return nil
+	}
+
+	queryApp, ok := app.(queryContextApp)
+	if !ok {
+		return errors.New("the app cannot create a context to check its invariants")
+	}
+
+	ctx, err := queryApp.CreateQueryContext(height, false)
+	if err != nil {
+		return err
+	}
+
+	registry := make(invariantRegistry)
+	invariantsApp.RegisterInvariants(registry)
+
+	routes := make([]string, 0, len(registry))
+	for route := range registry {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	var errs []error
+	for _, route := range routes {
+		if msg, broken := registry[route](ctx); broken {
+			errs = append(errs, fmt.Errorf("invariant %s broken: %s", route, msg))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// invariantRegistry collects the invariants registered by an app, by route.
+type invariantRegistry map[string]sdk.Invariant
+
+func (r invariantRegistry) RegisterRoute(moduleName, route string, invariant sdk.Invariant) {
+	r[moduleName+"/"+route] = invariant
+}
+
+// exportGenesis exports the state of the stopped first validator into its genesis.
+func (n *Network) exportGenesis(opts ExportOptions) (*genutiltypes.AppGenesis, error) {
+	v := n.Validators[0]
+
+	app := n.Config.AppConstructor(*v)
+	defer app.Close()
+
+	if err := requireOnDiskState(app, "exporting the state"); err != nil {
+		return nil, err
+	}
+
+	exporter, ok := app.(ExportableApp)
+	if !ok {
+		return nil, errors.New("the app does not implement ExportAppStateAndValidators")
+	}
+
+	if err := checkInvariants(app, 0); err != nil {
+		return nil, fmt.Errorf("before exporting: %w", err)
+	}
+
+	exported, err := exporter.ExportAppStateAndValidators(opts.ForZeroHeight, opts.JailAllowedAddrs, opts.ModulesToExport)
+	if err != nil {
+		return nil, fmt.Errorf("exporting state: %w", err)
+	}
+
+	genesis, err := genutiltypes.AppGenesisFromFile(v.Ctx.Config.GenesisFile())
+	if err != nil {
+		return nil, err
+	}
+
+	genesis.AppState = exported.AppState
+	genesis.InitialHeight = exported.Height
+	genesis.Consensus = genutiltypes.NewConsensusGenesis(exported.ConsensusParams, exported.Validators)
+	// the ABCI params are left out of the consensus genesis by the SDK
+	if exported.ConsensusParams.Abci != nil {
+		genesis.Consensus.Params.ABCI.VoteExtensionsEnableHeight = exported.ConsensusParams.Abci.VoteExtensionsEnableHeight
+	}
+
+	return genesis, nil
+}
+
+// resetValidator replaces the data of the stopped validator with the state of a fresh chain starting from the
+// genesis. The previous data dir is kept next to the new one.
+func (n *Network) resetValidator(v *Validator, genesis *genutiltypes.AppGenesis) error {
+	cmtCfg := v.Ctx.Config
+
+	dataDir := filepath.Join(cmtCfg.RootDir, "data")
+	if err := os.Rename(dataDir, fmt.Sprintf("%s.%d", dataDir, genesis.InitialHeight)); err != nil {
+		return err
+	}
+	v.closeDBs()
+
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return err
+	}
+
+	// the validator has not signed anything on the new chain
+	privval.LoadFilePVEmptyState(cmtCfg.PrivValidatorKeyFile(), cmtCfg.PrivValidatorStateFile()).Save()
+
+	// a full node which state synced trusts a block of the previous chain, it replays the new one from its genesis
+	cmtCfg.StateSync.Enable = false
+	cmtCfg.StateSync.RPCServers = nil
+	cmtCfg.StateSync.TrustHeight = 0
+	cmtCfg.StateSync.TrustHash = ""
+
+	return genesis.SaveAs(cmtCfg.GenesisFile())
+}
+
+// exportableApp is the app built by NewAppConstructor, which exports its state like the SDK apps do.
+type exportableApp struct {
+	*runtime.App
+
+	cdc            codec.Codec
+	stakingKeeper  *stakingkeeper.Keeper
+	distrKeeper    distrkeeper.Keeper
+	slashingKeeper slashingkeeper.Keeper
+	// inMemory is set if the app stores its state in memory, so that the state is lost once its node is stopped.
+	inMemory bool
+}
+
+// requireOnDiskState returns an error if the app was built by NewAppConstructor without WithOnDiskDB, so that
+// a new app built for a stopped node does not load the state of the previous one.
+func requireOnDiskState(app servertypes.Application, action string) error {
+	if exportable, ok := app.(*exportableApp); ok && exportable.inMemory {
+		return fmt.Errorf("%s requires the app to store its state on disk, build it with WithOnDiskDB", action)
+	}
+
+	return nil
+}
+
+// RegisterInvariants registers the invariants of the modules of the app.
+func (app *exportableApp) RegisterInvariants(ir sdk.InvariantRegistry) {
+	app.ModuleManager.RegisterInvariants(ir)
+}
+
+// ExportAppStateAndValidators exports the state of the app and its validators at its latest height.
+func (app *exportableApp) ExportAppStateAndValidators(forZeroHeight bool, jailAllowedAddrs, modulesToExport []string) (servertypes.ExportedApp, error) {
+	if app.stakingKeeper == nil {
+		return servertypes.ExportedApp{}, errors.New("the app has no staking module to export the validators of")
+	}
+
+	// as if they could withdraw from the start of the next block
+	ctx := app.NewContextLegacy(true, cmtproto.Header{Height: app.LastBlockHeight()})
+
+	// the state is exported at the next height, at which the new chain is initialized
+	height := app.LastBlockHeight() + 1
+	if forZeroHeight {
+		height = 0
+
+		if err := app.prepForZeroHeightGenesis(ctx, jailAllowedAddrs); err != nil {
+			return servertypes.ExportedApp{}, err
+		}
+	}
+
+	genState, err := app.ModuleManager.ExportGenesisForModules(ctx, app.cdc, modulesToExport)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	appState, err := json.MarshalIndent(genState, "", "  ")
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	validators, err := staking.WriteValidators(ctx, app.stakingKeeper)
+	if err != nil {
+		return servertypes.ExportedApp{}, err
+	}
+
+	return servertypes.ExportedApp{
+		AppState:        appState,
+		Validators:      validators,
+		Height:          height,
+		ConsensusParams: app.GetConsensusParams(ctx),
+	}, nil
+}
+
+// prepForZeroHeightGenesis prepares the state for a chain starting at height zero: the distribution rewards are
+// withdrawn and reset, the heights stored by staking and slashing are reset, and the validators that are not
+// allowed are jailed. It follows the export of simapp.
+func (app *exportableApp) prepForZeroHeightGenesis(ctx sdk.Context, jailAllowedAddrs []string) error {
+	allowedAddrs := make(map[string]bool)
+	for _, addr := range jailAllowedAddrs {
+		if _, err := sdk.ValAddressFromBech32(addr); err != nil {
+			return err
+		}
+		allowedAddrs[addr] = true
+	}
+
+	validators, err := app.stakingKeeper.GetAllValidators(ctx)
+	if err != nil {
+		return err
+	}
+
+	delegations, err := app.stakingKeeper.GetAllDelegations(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := app.ModuleManager.Modules[distrtypes.ModuleName]; ok {
+		if err := app.resetDistribution(ctx, validators, delegations); err != nil {
+			return err
+		}
+	}
+
+	// reset the creation heights of redelegations and unbonding delegations
+	var setErr error
+	if err := app.stakingKeeper.IterateRedelegations(ctx, func(_ int64, red stakingtypes.Redelegation) bool {
+		for i := range red.Entries {
+			red.Entries[i].CreationHeight = 0
+		}
+		setErr = app.stakingKeeper.SetRedelegation(ctx, red)
+		return setErr != nil
+	}); err != nil {
+		return err
+	}
+	if setErr != nil {
+		return setErr
+	}
+
+	if err := app.stakingKeeper.IterateUnbondingDelegations(ctx, func(_ int64, ubd stakingtypes.UnbondingDelegation) bool {
+		for i := range ubd.Entries {
+			ubd.Entries[i].CreationHeight = 0
+		}
+		setErr = app.stakingKeeper.SetUnbondingDelegation(ctx, ubd)
+		return setErr != nil
+	}); err != nil {
+		return err
+	}
+	if setErr != nil {
+		return setErr
+	}
+
+	// reset the unbonding heights of validators and jail the validators that are not allowed
+	for _, validator := range validators {
+		validator.UnbondingHeight = 0
+		if len(allowedAddrs) > 0 && !allowedAddrs[validator.GetOperator()] {
+			validator.Jailed = true
+		}
+
+		if err := app.stakingKeeper.SetValidator(ctx, validator); err != nil {
+			return err
+		}
+	}
+
+	if _, err := app.stakingKeeper.ApplyAndReturnValidatorSetUpdates(ctx); err != nil {
+		return err
+	}
+
+	if _, ok := app.ModuleManager.Modules[slashingtypes.ModuleName]; ok {
+		// reset the start heights of signing infos
+		if err := app.slashingKeeper.IterateValidatorSigningInfos(ctx, func(addr sdk.ConsAddress, info slashingtypes.ValidatorSigningInfo) bool {
+			info.StartHeight = 0
+			setErr = app.slashingKeeper.SetValidatorSigningInfo(ctx, addr, info)
+			return setErr != nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	return setErr
+}
+
+// resetDistribution withdraws all the commissions and rewards, and reinitializes the distribution state of the
+// validators and delegations as if they were created at height zero.
+func (app *exportableApp) resetDistribution(ctx sdk.Context, validators []stakingtypes.Validator, delegations []stakingtypes.Delegation) error {
+	valAddrs := make([]sdk.ValAddress, len(validators))
+	for i, validator := range validators {
+		valAddr, err := app.stakingKeeper.ValidatorAddressCodec().StringToBytes(validator.GetOperator())
+		if err != nil {
+			return err
+		}
+		valAddrs[i] = valAddr
+
+		// validators without commission fail to withdraw it
+		_, _ = app.distrKeeper.WithdrawValidatorCommission(ctx, valAddr)
+	}
+
+	for _, delegation := range delegations {
+		valAddr, err := sdk.ValAddressFromBech32(delegation.ValidatorAddress)
+		if err != nil {
+			return err
+		}
+
+		delAddr, err := sdk.AccAddressFromBech32(delegation.DelegatorAddress)
+		if err != nil {
+			return err
+		}
+
+		if _, err := app.distrKeeper.WithdrawDelegationRewards(ctx, delAddr, valAddr); err != nil {
+			return err
+		}
+	}
+
+	app.distrKeeper.DeleteAllValidatorSlashEvents(ctx)
+	app.distrKeeper.DeleteAllValidatorHistoricalRewards(ctx)
+
+	ctx = ctx.WithBlockHeight(0)
+
+	for _, valAddr := range valAddrs {
+		// donate the remaining fractions of the outstanding rewards to the community pool
+		scraps, err := app.distrKeeper.GetValidatorOutstandingRewardsCoins(ctx, valAddr)
+		if err != nil {
+			return err
+		}
+
+		feePool, err := app.distrKeeper.FeePool.Get(ctx)
+		if err != nil {
+			return err
+		}
+
+		feePool.CommunityPool = feePool.CommunityPool.Add(scraps...)
+		if err := app.distrKeeper.FeePool.Set(ctx, feePool); err != nil {
+			return err
+		}
+
+		if err := app.distrKeeper.Hooks().AfterValidatorCreated(ctx, valAddr); err != nil {
+			return err
+		}
+	}
+
+	for _, delegation := range delegations {
+		valAddr, err := sdk.ValAddressFromBech32(delegation.ValidatorAddress)
+		if err != nil {
+			return err
+		}
+
+		delAddr, err := sdk.AccAddressFromBech32(delegation.DelegatorAddress)
+		if err != nil {
+			return err
+		}
+
+		if err := app.distrKeeper.Hooks().BeforeDelegationCreated(ctx, delAddr, valAddr); err != nil {
+			return err
+		}
+
+		if err := app.distrKeeper.Hooks().AfterDelegationModified(ctx, delAddr, valAddr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
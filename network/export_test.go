@@ -0,0 +1,61 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestExportAndRestart(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("in memory", func(t *testing.T) {
+		cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+		s := network.NewSuite(t, cfg)
+
+		_, err := s.ExportAndRestart(ctx, network.ExportOptions{})
+		require.ErrorContains(t, err, "WithOnDiskDB")
+	})
+
+	t.Run("on disk", func(t *testing.T) {
+		appConfig := sdknetwork.MinimumAppConfig()
+		cfg := network.NewConfig(appConfig)
+		cfg.AppConstructor = network.NewAppConstructor(appConfig, "", network.WithOnDiskDB())
+		s := network.NewSuite(t, cfg)
+
+		height, err := s.Network.WaitForHeightWithTimeout(3, time.Minute)
+		require.NoError(t, err)
+
+		genesis, err := s.ExportAndRestart(ctx, network.ExportOptions{})
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, genesis.InitialHeight, height)
+
+		_, err = s.Network.WaitForHeightWithTimeout(genesis.InitialHeight+1, time.Minute)
+		require.NoError(t, err)
+	})
+
+	t.Run("state synced full node", func(t *testing.T) {
+		appConfig := sdknetwork.MinimumAppConfig()
+		cfg := network.NewConfig(appConfig, network.WithSnapshots(2, 0))
+		cfg.AppConstructor = network.NewAppConstructor(appConfig, "", network.WithOnDiskDB())
+		s := network.NewSuite(t, cfg)
+
+		_, err := s.Network.WaitForHeightWithTimeout(4, time.Minute)
+		require.NoError(t, err)
+		node := s.RequireStateSyncNode(t, time.Minute)
+
+		genesis, err := s.ExportAndRestart(ctx, network.ExportOptions{})
+		require.NoError(t, err)
+
+		// the full node replays the new chain instead of state syncing from a block of the previous one
+		require.Eventually(t, func() bool {
+			status, err := node.RPCClient.Status(ctx)
+			return err == nil && status.SyncInfo.LatestBlockHeight > genesis.InitialHeight
+		}, time.Minute, time.Second, "full node did not follow the restarted chain")
+	})
+}
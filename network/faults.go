@@ -0,0 +1,329 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// peerFilterPath prefixes the ABCI queries of CometBFT asking whether a node accepts a connection or a peer.
+	peerFilterPath = "/p2p/filter/"
+	// peerIDFilterPath prefixes the ABCI queries of CometBFT asking whether a node accepts a peer, by ID.
+	peerIDFilterPath = peerFilterPath + "id/"
+)
+
+// IsRunning returns whether the node of the validator is running.
+func (v Validator) IsRunning() bool {
+	return v.tmNode != nil && v.tmNode.IsRunning()
+}
+
+// runningValidator returns the first validator of the network whose node is running.
+func (n *Network) runningValidator() (*Validator, error) {
+	if len(n.Validators) == 0 {
+		return nil, errors.New("no validators available")
+	}
+
+	for _, v := range n.Validators {
+		if v.IsRunning() {
+			return v, nil
+		}
+	}
+
+	return nil, errors.New("no running validators")
+}
+
+// StopValidator stops the node, gRPC and API servers of the i-th validator. Its data dir is kept, so that it
+// can be restarted with StartValidator.
+func (n *Network) StopValidator(i int) error {
+	return n.stopNode("validator", n.Validators, i)
+}
+
+// StartValidator restarts the i-th validator from its data dir, after it was stopped with StopValidator. The
+// application replays the blocks committed by the node before it was stopped, and the node then catches up
+// with its peers.
+func (n *Network) StartValidator(i int) error {
+	return n.startNode("validator", n.Validators, i)
+}
+
+// StopFullNode stops the i-th full node like StopValidator.
+func (n *Network) StopFullNode(i int) error {
+	return n.stopNode("full node", n.FullNodes, i)
+}
+
+// StartFullNode restarts the i-th full node like StartValidator, after it was stopped with StopFullNode.
+func (n *Network) StartFullNode(i int) error {
+	return n.startNode("full node", n.FullNodes, i)
+}
+
+// stopNode stops the i-th of the nodes, of the given kind.
+func (n *Network) stopNode(kind string, nodes []*Validator, i int) error {
+	if i < 0 || i >= len(nodes) {
+		return fmt.Errorf("%s %d out of range, the network has %d %ss", kind, i, len(nodes), kind)
+	}
+
+	v := nodes[i]
+	if !v.IsRunning() {
+		return fmt.Errorf("%s %d is not running", kind, i)
+	}
+
+	n.Logger.Logf("stopping %s %d...", kind, i)
+	n.stopValidator(v)
+
+	return nil
+}
+
+// startNode restarts the i-th of the nodes, of the given kind.
+func (n *Network) startNode(kind string, nodes []*Validator, i int) error {
+	if i < 0 || i >= len(nodes) {
+		return fmt.Errorf("%s %d out of range, the network has %d %ss", kind, i, len(nodes), kind)
+	}
+
+	v := nodes[i]
+	if v.IsRunning() {
+		return fmt.Errorf("%s %d is already running", kind, i)
+	}
+
+	n.Logger.Logf("starting %s %d...", kind, i)
+	return startInProcess(n.Config, v)
+}
+
+// Partition splits the network into the given groups of nodes, validators or full nodes, e.g.
+// Partition(n.Validators[:3], n.Validators[3:]). The connections between nodes of different groups are closed,
+// and the nodes reject each other as peers until Heal is called, so that the groups stop exchanging blocks,
+// votes and txs. Nodes that are not part of any group, including the full nodes added later, are isolated
+// from all the others. A previous partition is replaced.
+func (n *Network) Partition(groups ...[]*Validator) error {
+	nodeGroups := make(map[p2p.ID]int)
+	for g, group := range groups {
+		for _, v := range group {
+			if _, ok := nodeGroups[p2p.ID(v.NodeID)]; ok {
+				return fmt.Errorf("%s is part of several groups", v.Moniker)
+			}
+			nodeGroups[p2p.ID(v.NodeID)] = g
+		}
+	}
+
+	n.Logger.Logf("partitioning the network into %d groups...", len(groups))
+	n.partition.set(nodeGroups)
+
+	// the peer filters only apply to new connections
+	for _, v := range n.nodes() {
+		if !v.IsRunning() {
+			continue
+		}
+
+		sw := v.tmNode.Switch()
+		for _, peer := range sw.Peers().List() {
+			if !n.partition.connected(p2p.ID(v.NodeID), peer.ID()) {
+				sw.StopPeerGracefully(peer)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Heal ends the partition of the network made with Partition. The nodes dial their persistent peers again
+// instead of waiting for their reconnection backoff to expire.
+func (n *Network) Heal() error {
+	n.Logger.Log("healing the network partition...")
+	n.partition.set(nil)
+
+	for _, v := range n.nodes() {
+		if !v.IsRunning() || v.Ctx.Config.P2P.PersistentPeers == "" {
+			continue
+		}
+
+		peers := strings.Split(v.Ctx.Config.P2P.PersistentPeers, ",")
+		if err := v.tmNode.Switch().DialPeersAsync(peers); err != nil {
+			return fmt.Errorf("failed to dial the peers of %s: %w", v.Moniker, err)
+		}
+	}
+
+	return nil
+}
+
+// partition holds the groups of nodes a network is partitioned into. It is shared by the nodes of the network,
+// whose peer filters only accept the peers of their own group.
+type partition struct {
+	mu sync.RWMutex
+	// groups maps the IDs of the partitioned nodes to their group. The network is not partitioned if nil.
+	groups map[p2p.ID]int
+}
+
+// set replaces the groups of the partition.
+func (p *partition) set(groups map[p2p.ID]int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.groups = groups
+}
+
+// connected returns whether the nodes with the given IDs may be peers.
+func (p *partition) connected(a, b p2p.ID) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.groups == nil {
+		return true
+	}
+
+	groupA, okA := p.groups[a]
+	groupB, okB := p.groups[b]
+	return okA && okB && groupA == groupB
+}
+
+// wrap returns the ABCI application of the node with the given ID, rejecting the peers outside of its group.
+// CometBFT only queries it if the node filters its peers.
+func (p *partition) wrap(nodeID string, app abci.Application) abci.Application {
+	return partitionedApp{Application: app, nodeID: p2p.ID(nodeID), partition: p}
+}
+
+// partitionedApp is the ABCI application of a node rejecting the peers outside of its group.
+type partitionedApp struct {
+	abci.Application
+
+	nodeID    p2p.ID
+	partition *partition
+}
+
+// Query answers the peer filter queries without the app, as CometBFT sends them while the gRPC services are
+// still being registered on the app once the node started. Connections are accepted from any address.
+func (a partitionedApp) Query(ctx context.Context, req *abci.RequestQuery) (*abci.ResponseQuery, error) {
+	if !strings.HasPrefix(req.Path, peerFilterPath) {
+		return a.Application.Query(ctx, req)
+	}
+
+	if peerID, ok := strings.CutPrefix(req.Path, peerIDFilterPath); ok && !a.partition.connected(a.nodeID, p2p.ID(peerID)) {
+		return &abci.ResponseQuery{Code: 1, Log: fmt.Sprintf("peer %s is partitioned from %s", peerID, a.nodeID)}, nil
+	}
+
+	return &abci.ResponseQuery{}, nil
+}
+
+// OnlineVotingPower returns the voting power of the validators whose node is running and the total voting
+// power of the validator set at the latest height. The network keeps committing blocks as long as the online
+// voting power is more than 2/3 of the total.
+func (n *Network) OnlineVotingPower() (online, total int64, err error) {
+	val, err := n.runningValidator()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	perPage := 100
+	for page := 1; ; page++ {
+		resp, err := val.RPCClient.Validators(context.Background(), nil, &page, &perPage)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		for _, cmtVal := range resp.Validators {
+			total += cmtVal.VotingPower
+
+			for _, v := range n.Validators {
+				if v.IsRunning() && bytes.Equal(v.PubKey.Address(), cmtVal.Address) {
+					online += cmtVal.VotingPower
+				}
+			}
+		}
+
+		if page*perPage >= resp.Total {
+			return online, total, nil
+		}
+	}
+}
+
+// StopNode stops the targeted node. See Network.StopValidator.
+func (s *TestSuite) StopNode() error {
+	if i, ok := s.fullNodeIndex(); ok {
+		return s.Network.StopFullNode(i)
+	}
+
+	return s.Network.StopValidator(s.node)
+}
+
+// StartNode restarts the targeted node after it was stopped with StopNode. See Network.StartValidator.
+func (s *TestSuite) StartNode() error {
+	var err error
+	if i, ok := s.fullNodeIndex(); ok {
+		err = s.Network.StartFullNode(i)
+	} else {
+		err = s.Network.StartValidator(s.node)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.clientCache().resetBackoff(s.Validator().AppConfig.GRPC.Address)
+	return nil
+}
+
+// StopNodeForBlocks stops the targeted validator's node until the rest of the network has committed the given
+// number of blocks, then restarts it. The node is restarted even if the blocks were not committed within the
+// timeout, e.g. because the chain halted, in which case an error is returned.
+func (s *TestSuite) StopNodeForBlocks(blocks int64, timeout time.Duration) error {
+	height, err := s.Network.LatestHeight()
+	if err != nil {
+		return err
+	}
+
+	if err := s.StopNode(); err != nil {
+		return err
+	}
+
+	_, waitErr := s.Network.WaitForHeightWithTimeout(height+blocks, timeout)
+	if waitErr != nil {
+		waitErr = fmt.Errorf("waiting for %d blocks with node %d offline: %w", blocks, s.node, waitErr)
+	}
+
+	return errors.Join(waitErr, s.StartNode())
+}
+
+// RequireProgress fails the test if the network does not commit a new block within the timeout.
+func (s *TestSuite) RequireProgress(t testing.TB, timeout time.Duration) {
+	t.Helper()
+
+	height, err := s.Network.LatestHeight()
+	require.NoError(t, err)
+
+	_, err = s.Network.WaitForHeightWithTimeout(height+1, timeout)
+	require.NoError(t, err, "network halted at height %d", height)
+}
+
+// RequireHalt fails the test if the network commits a new block within the given duration. A block that was
+// already being committed when the validators were stopped may still be committed, so the halt should be
+// asserted for at least a few block times.
+func (s *TestSuite) RequireHalt(t testing.TB, duration time.Duration) {
+	t.Helper()
+
+	height, err := s.Network.LatestHeight()
+	require.NoError(t, err)
+
+	latest, err := s.Network.WaitForHeightWithTimeout(height+1, duration)
+	require.Error(t, err, "network committed height %d after halting at height %d", latest, height)
+}
+
+// RequireExpectedLiveness asserts that the network keeps committing blocks if more than 2/3 of the voting
+// power is online, and that it halts otherwise.
+func (s *TestSuite) RequireExpectedLiveness(t testing.TB, timeout time.Duration) {
+	t.Helper()
+
+	online, total, err := s.Network.OnlineVotingPower()
+	require.NoError(t, err)
+
+	if 3*online > 2*total {
+		s.RequireProgress(t, timeout)
+	} else {
+		s.RequireHalt(t, timeout)
+	}
+}
@@ -0,0 +1,98 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestPartition(t *testing.T) {
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 4
+	s := network.NewSuite(t, cfg)
+	vals := s.Network.Validators
+
+	_, err := s.Network.WaitForHeightWithTimeout(2, time.Minute)
+	require.NoError(t, err)
+
+	// the majority holds 3/4 of the voting power, so it keeps committing blocks without the isolated validator
+	require.NoError(t, s.Network.Partition(vals[:3], vals[3:]))
+	isolated := s.Node(3)
+	isolatedHeight := nodeHeight(t, isolated)
+
+	s.RequireProgress(t, time.Minute)
+	s.RequireProgress(t, time.Minute)
+	require.LessOrEqual(t, nodeHeight(t, isolated), isolatedHeight+1, "isolated validator kept committing blocks")
+
+	// no group holds more than 2/3 of the voting power
+	require.NoError(t, s.Network.Partition(vals[:2], vals[2:]))
+	s.RequireHalt(t, 10*time.Second)
+
+	require.NoError(t, s.Network.Heal())
+	s.RequireProgress(t, time.Minute)
+
+	height, err := s.Network.LatestHeight()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return nodeHeight(t, isolated) >= height
+	}, time.Minute, 100*time.Millisecond, "isolated validator did not catch up after the partition healed")
+}
+
+// nodeHeight returns the latest height committed by the targeted node.
+func nodeHeight(t *testing.T, s *network.TestSuite) int64 {
+	t.Helper()
+
+	status, err := s.Validator().RPCClient.Status(context.Background())
+	require.NoError(t, err)
+
+	return status.SyncInfo.LatestBlockHeight
+}
+
+func TestExpectedLiveness(t *testing.T) {
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 4
+	s := network.NewSuite(t, cfg)
+
+	_, err := s.Network.WaitForHeightWithTimeout(2, time.Minute)
+	require.NoError(t, err)
+
+	online, total, err := s.Network.OnlineVotingPower()
+	require.NoError(t, err)
+	require.Equal(t, total, online)
+	// the validators are bonded with the same stake, so they hold the same voting power
+	power := total / 4
+	require.Equal(t, 4*power, total)
+
+	// the rest of the network holds 3/4 of the voting power, so it commits the blocks without the stopped node
+	require.NoError(t, s.Node(3).StopNodeForBlocks(3, time.Minute))
+	require.True(t, s.Network.Validators[3].IsRunning())
+
+	require.NoError(t, s.Node(3).StopNode())
+	online, _, err = s.Network.OnlineVotingPower()
+	require.NoError(t, err)
+	require.Equal(t, 3*power, online)
+	s.RequireExpectedLiveness(t, time.Minute)
+
+	// half of the voting power is not enough to commit blocks
+	require.NoError(t, s.Node(2).StopNode())
+	online, _, err = s.Network.OnlineVotingPower()
+	require.NoError(t, err)
+	require.Equal(t, 2*power, online)
+	s.RequireExpectedLiveness(t, 10*time.Second)
+
+	// the node is restarted although the blocks could not be committed
+	require.Error(t, s.Node(1).StopNodeForBlocks(1, 5*time.Second))
+	require.True(t, s.Network.Validators[1].IsRunning())
+
+	require.NoError(t, s.Node(2).StartNode())
+	require.NoError(t, s.Node(3).StartNode())
+	online, _, err = s.Network.OnlineVotingPower()
+	require.NoError(t, err)
+	require.Equal(t, total, online)
+	s.RequireExpectedLiveness(t, time.Minute)
+}
@@ -0,0 +1,244 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+)
+
+// stateSyncDiscoveryTime is how long a state syncing node discovers the snapshots of its peers, the minimum
+// allowed by CometBFT.
+const stateSyncDiscoveryTime = 5 * time.Second
+
+// WithFullNodes returns a ConfigOption adding n non-validator full nodes to the network, which are started
+// after the validators. The full nodes are indexed after the validators, e.g. in WithTopology.
+func WithFullNodes(n int) ConfigOption {
+	return func(cfg *Config) error {
+		if n < 0 {
+			return fmt.Errorf("invalid number of full nodes %d", n)
+		}
+
+		cfg.NumFullNodes = n
+		return nil
+	}
+}
+
+// WithTopology returns a ConfigOption setting the persistent peers of the nodes of the network, by node
+// index: the validators come first, followed by the full nodes added with WithFullNodes. The peer exchange is
+// disabled, so that nodes only connect to their persistent peers and to the nodes having them as persistent
+// peers. A node missing from the topology has no persistent peers.
+//
+// For example, the following topology hides the first validator behind a sentry full node:
+//
+//	network.WithTopology(map[int][]int{
+//		0: {2}, // validator 0 only connects to the sentry
+//		1: {2},
+//		2: {0, 1}, // full node 2 is the sentry
+//	})
+func WithTopology(topology map[int][]int) ConfigOption {
+	return func(cfg *Config) error {
+		cfg.Topology = topology
+		return nil
+	}
+}
+
+// NodeOption configures a full node added to the network with AddFullNode.
+type NodeOption func(*NodeOptions)
+
+// NodeOptions represents the options used to add a full node to the network.
+type NodeOptions struct {
+	// StateSync syncs the node from a snapshot of its peers instead of replaying the blocks from genesis.
+	StateSync bool
+	// PersistentPeers are the indexes of the nodes the node connects to. The node connects to the validators
+	// if nil.
+	PersistentPeers []int
+}
+
+// WithStateSync syncs the full node from the state sync snapshots of the validators, which must be enabled with
+// WithSnapshots. The node then catches up with the blocks committed since the snapshot.
+func WithStateSync() NodeOption {
+	return func(options *NodeOptions) {
+		options.StateSync = true
+	}
+}
+
+// WithPersistentPeers connects the full node to the nodes of the network at the given indexes, validators
+// first, instead of the validators. The peer exchange of the node is disabled, so that it only connects to
+// these peers, e.g. to add a node behind a sentry.
+func WithPersistentPeers(nodes ...int) NodeOption {
+	return func(options *NodeOptions) {
+		options.PersistentPeers = append([]int{}, nodes...)
+	}
+}
+
+// AddFullNode adds a non-validator node to the running network, peered with the validators, and starts it. The
+// node runs the app of the network and exposes its own RPC, API and gRPC servers like the validators, and is
+// targeted by the view of the suite returned by TestSuite.FullNode. It is stopped when the network is cleaned
+// up.
+func (n *Network) AddFullNode(options ...NodeOption) (*Validator, error) {
+	var no NodeOptions
+	for _, option := range options {
+		option(&no)
+	}
+
+	if no.StateSync && n.Config.SnapshotInterval == 0 {
+		return nil, errors.New("state sync requires the validators to take snapshots, see WithSnapshots")
+	}
+
+	v, err := n.newFullNode(len(n.Validators) + len(n.FullNodes))
+	if err != nil {
+		return nil, err
+	}
+	// release the ports reserved for the node if it fails to be configured, startInProcess releases them otherwise
+	defer v.ports.release()
+
+	if no.PersistentPeers != nil {
+		if err := n.setPersistentPeers(v, no.PersistentPeers); err != nil {
+			return nil, err
+		}
+	}
+
+	if no.StateSync {
+		if err := n.configureStateSync(v); err != nil {
+			return nil, err
+		}
+	}
+
+	n.Logger.Logf("starting full node %d...", len(n.FullNodes))
+	if err := startInProcess(n.Config, v); err != nil {
+		return nil, err
+	}
+	n.FullNodes = append(n.FullNodes, v)
+
+	return v, nil
+}
+
+// newFullNode prepares the i-th node of the network as a full node with the genesis of the validators, peered
+// with the validators.
+func (n *Network) newFullNode(i int) (_ *Validator, err error) {
+	v, err := newNode(n.Config, n.BaseDir, i)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			v.ports.release()
+		}
+	}()
+	v.partition = n.partition
+
+	cmtCfg := v.Ctx.Config
+	if n.Config.Seed != 0 {
+		if err := writeSeededNodeKeys(cmtCfg, n.Config.Seed, i); err != nil {
+			return nil, err
+		}
+	}
+
+	// the node generates a consensus key like any node, but it is not part of the validator set
+	if v.NodeID, _, err = genutil.InitializeNodeValidatorFiles(cmtCfg); err != nil {
+		return nil, err
+	}
+
+	genesis, err := os.ReadFile(n.Validators[0].Ctx.Config.GenesisFile())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(cmtCfg.GenesisFile(), genesis, 0o600); err != nil {
+		return nil, err
+	}
+
+	peers := make([]string, len(n.Validators))
+	for j, val := range n.Validators {
+		if peers[j], err = peerAddress(val); err != nil {
+			return nil, err
+		}
+	}
+	cmtCfg.P2P.PersistentPeers = strings.Join(peers, ",")
+
+	v.ClientCtx = client.Context{}.
+		WithHomeDir(cmtCfg.RootDir).
+		WithChainID(n.Config.ChainID).
+		WithInterfaceRegistry(n.Config.InterfaceRegistry).
+		WithCodec(n.Config.Codec).
+		WithLegacyAmino(n.Config.LegacyAmino).
+		WithTxConfig(n.Config.TxConfig).
+		WithAccountRetriever(n.Config.AccountRetriever)
+
+	return v, nil
+}
+
+// setPersistentPeers sets the persistent peers of the node to the nodes of the network at the given indexes, and
+// disables its peer exchange so that it does not connect to other nodes.
+func (n *Network) setPersistentPeers(v *Validator, indexes []int) error {
+	nodes := n.nodes()
+
+	peers := make([]string, len(indexes))
+	for j, i := range indexes {
+		if i < 0 || i >= len(nodes) {
+			return fmt.Errorf("peer %d out of range, the network has %d nodes", i, len(nodes))
+		}
+		if nodes[i] == v {
+			return fmt.Errorf("node %d cannot be its own peer", i)
+		}
+
+		var err error
+		if peers[j], err = peerAddress(nodes[i]); err != nil {
+			return err
+		}
+	}
+
+	v.Ctx.Config.P2P.PersistentPeers = strings.Join(peers, ",")
+	v.Ctx.Config.P2P.PexReactor = false
+
+	return nil
+}
+
+// configureStateSync configures the node to state sync from the running validators, trusting their latest block.
+func (n *Network) configureStateSync(v *Validator) error {
+	val, err := n.runningValidator()
+	if err != nil {
+		return err
+	}
+
+	block, err := val.RPCClient.Block(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	var rpcServers []string
+	for _, val := range n.Validators {
+		if val.IsRunning() {
+			rpcServers = append(rpcServers, val.RPCAddress)
+		}
+	}
+	// CometBFT requires a second RPC server to cross-check the light blocks
+	if len(rpcServers) == 1 {
+		rpcServers = append(rpcServers, rpcServers[0])
+	}
+
+	stateSync := v.Ctx.Config.StateSync
+	stateSync.Enable = true
+	stateSync.RPCServers = rpcServers
+	stateSync.TrustHeight = block.Block.Height
+	stateSync.TrustHash = block.BlockID.Hash.String()
+	stateSync.DiscoveryTime = stateSyncDiscoveryTime
+
+	return nil
+}
+
+// peerAddress returns the address at which the node of the validator is dialed by its peers.
+func peerAddress(v *Validator) (string, error) {
+	p2pURL, err := url.Parse(v.P2PAddress)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s@%s:%s", v.NodeID, p2pURL.Hostname(), p2pURL.Port()), nil
+}
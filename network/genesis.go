@@ -0,0 +1,205 @@
+package network
+
+import (
+	"encoding/json"
+
+	sdkmath "cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/cosmos/gogoproto/proto"
+)
+
+// GenesisModifier modifies the genesis state of a network config.
+type GenesisModifier func(cfg *Config) error
+
+// genesisState is a constraint for pointers to module genesis states, so that a genesis state can be
+// referenced by its value type when unmarshalling it.
+type genesisState[T any] interface {
+	*T
+	proto.Message
+}
+
+// ModifyGenesis applies the given genesis modifiers to the config in order. The resulting genesis state is
+// validated by the modules once the network is created.
+func ModifyGenesis(cfg *Config, modifiers ...GenesisModifier) error {
+	for _, modifier := range modifiers {
+		if err := modifier(cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WithGenesis unmarshals the genesis state of the given module from the config, calls fn to mutate it and
+// marshals the result back into the config.
+//
+//	err := network.WithGenesis(&cfg, banktypes.ModuleName, func(gs *banktypes.GenesisState) {
+//		gs.Params.DefaultSendEnabled = false
+//	})
+func WithGenesis[T any, PT genesisState[T]](cfg *Config, moduleName string, fn func(*T)) error {
+	return ModuleGenesis[T, PT](moduleName, fn)(cfg)
+}
+
+// ModuleGenesis returns a GenesisModifier that mutates the genesis state of the given module with fn.
+// See WithGenesis.
+func ModuleGenesis[T any, PT genesisState[T]](moduleName string, fn func(*T)) GenesisModifier {
+	return func(cfg *Config) error {
+		gs := PT(new(T))
+		if err := cfg.Codec.UnmarshalJSON(cfg.GenesisState[moduleName], gs); err != nil {
+			return err
+		}
+
+		fn(gs)
+
+		bz, err := cfg.Codec.MarshalJSON(gs)
+		if err != nil {
+			return err
+		}
+
+		cfg.GenesisState[moduleName] = bz
+		return nil
+	}
+}
+
+// SetParams returns a GenesisModifier that replaces the params of the given module. It applies to any module
+// that stores its params under the "params" field of its genesis state, as all SDK modules do.
+func SetParams(moduleName string, params proto.Message) GenesisModifier {
+	return func(cfg *Config) error {
+		var gs map[string]json.RawMessage
+		if err := json.Unmarshal(cfg.GenesisState[moduleName], &gs); err != nil {
+			return err
+		}
+
+		paramsBz, err := cfg.Codec.MarshalJSON(params)
+		if err != nil {
+			return err
+		}
+		gs["params"] = paramsBz
+
+		bz, err := json.Marshal(gs)
+		if err != nil {
+			return err
+		}
+
+		cfg.GenesisState[moduleName] = bz
+		return nil
+	}
+}
+
+// AddAccounts returns a GenesisModifier that adds the given accounts to the auth genesis state.
+func AddAccounts(accounts ...authtypes.GenesisAccount) GenesisModifier {
+	return func(cfg *Config) error {
+		packedAccounts, err := authtypes.PackAccounts(accounts)
+		if err != nil {
+			return err
+		}
+
+		return WithGenesis(cfg, authtypes.ModuleName, func(gs *authtypes.GenesisState) {
+			gs.Accounts = append(gs.Accounts, packedAccounts...)
+		})
+	}
+}
+
+// AddBalances returns a GenesisModifier that adds the given balances to the bank genesis state. Balances of
+// addresses already present in genesis are added to the existing balance. If the genesis supply is set, it is
+// increased accordingly, otherwise it is computed from the balances at genesis.
+func AddBalances(balances ...banktypes.Balance) GenesisModifier {
+	return ModuleGenesis(banktypes.ModuleName, func(gs *banktypes.GenesisState) {
+		for _, balance := range balances {
+			if balance.Coins.IsZero() {
+				continue
+			}
+
+			gs.Balances = addBalance(gs.Balances, balance)
+
+			if !gs.Supply.Empty() {
+				gs.Supply = gs.Supply.Add(balance.Coins...)
+			}
+		}
+	})
+}
+
+// AddValidators returns a GenesisModifier that adds the given validators to the staking genesis state and funds
+// the bonded or not bonded pool with their tokens, according to their status.
+//
+// The network validators are created from gentxs, which is incompatible with validators being part of the
+// validator set at genesis. Validators added this way must therefore be jailed, or have no voting power.
+func AddValidators(validators ...stakingtypes.Validator) GenesisModifier {
+	return func(cfg *Config) error {
+		var gs stakingtypes.GenesisState
+		if err := cfg.Codec.UnmarshalJSON(cfg.GenesisState[stakingtypes.ModuleName], &gs); err != nil {
+			return err
+		}
+
+		bondedTokens, notBondedTokens := sdkmath.ZeroInt(), sdkmath.ZeroInt()
+		for _, validator := range validators {
+			if validator.IsBonded() {
+				bondedTokens = bondedTokens.Add(validator.Tokens)
+			} else {
+				notBondedTokens = notBondedTokens.Add(validator.Tokens)
+			}
+		}
+		gs.Validators = append(gs.Validators, validators...)
+
+		bz, err := cfg.Codec.MarshalJSON(&gs)
+		if err != nil {
+			return err
+		}
+		cfg.GenesisState[stakingtypes.ModuleName] = bz
+
+		var poolBalances []banktypes.Balance
+		if bondedTokens.IsPositive() {
+			poolBalances = append(poolBalances, banktypes.Balance{
+				Address: authtypes.NewModuleAddress(stakingtypes.BondedPoolName).String(),
+				Coins:   sdk.NewCoins(sdk.NewCoin(gs.Params.BondDenom, bondedTokens)),
+			})
+		}
+		if notBondedTokens.IsPositive() {
+			poolBalances = append(poolBalances, banktypes.Balance{
+				Address: authtypes.NewModuleAddress(stakingtypes.NotBondedPoolName).String(),
+				Coins:   sdk.NewCoins(sdk.NewCoin(gs.Params.BondDenom, notBondedTokens)),
+			})
+		}
+
+		return AddBalances(poolBalances...)(cfg)
+	}
+}
+
+// SetDenomMetadata returns a GenesisModifier that sets the given denom metadata in the bank genesis state,
+// replacing any existing metadata for the same base denom.
+func SetDenomMetadata(metadata ...banktypes.Metadata) GenesisModifier {
+	return ModuleGenesis(banktypes.ModuleName, func(gs *banktypes.GenesisState) {
+		for _, md := range metadata {
+			replaced := false
+			for i, existing := range gs.DenomMetadata {
+				if existing.Base == md.Base {
+					gs.DenomMetadata[i] = md
+					replaced = true
+					break
+				}
+			}
+
+			if !replaced {
+				gs.DenomMetadata = append(gs.DenomMetadata, md)
+			}
+		}
+	})
+}
+
+// addBalance adds the balance to the list, merging it with any existing balance of the same address.
+func addBalance(balances []banktypes.Balance, balance banktypes.Balance) []banktypes.Balance {
+	for i, existing := range balances {
+		if existing.Address == balance.Address {
+			balances[i].Coins = existing.Coins.Add(balance.Coins...)
+			return balances
+		}
+	}
+
+	return append(balances, banktypes.Balance{
+		Address: balance.Address,
+		Coins:   balance.Coins.Sort(),
+	})
+}
@@ -0,0 +1,78 @@
+package network_test
+
+import (
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+	"github.com/skip-mev/chaintestutil/sample"
+)
+
+func TestModifyGenesis(t *testing.T) {
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	r := sample.Rand()
+	addr := sample.AccAddress(r)
+	coins, otherCoins := sample.Coins(r), sample.Coins(r)
+
+	stakingParams := stakingtypes.DefaultParams()
+	stakingParams.MaxValidators = 7
+
+	metadata := banktypes.Metadata{
+		Description: "stake",
+		Base:        sdk.DefaultBondDenom,
+		Display:     sdk.DefaultBondDenom,
+		Name:        sdk.DefaultBondDenom,
+		Symbol:      "STAKE",
+		DenomUnits:  []*banktypes.DenomUnit{{Denom: sdk.DefaultBondDenom}},
+	}
+
+	require.NoError(t, network.ModifyGenesis(&cfg,
+		network.AddAccounts(authtypes.NewBaseAccount(addr, nil, 0, 0)),
+		network.AddBalances(
+			banktypes.Balance{Address: addr.String(), Coins: coins},
+			banktypes.Balance{Address: addr.String(), Coins: otherCoins},
+		),
+		network.SetParams(stakingtypes.ModuleName, &stakingParams),
+		network.SetDenomMetadata(metadata, metadata),
+	))
+	require.NoError(t, cfg.BasicManager.ValidateGenesis(cfg.Codec, cfg.TxConfig, cfg.GenesisState))
+
+	var authGenState authtypes.GenesisState
+	require.NoError(t, cfg.Codec.UnmarshalJSON(cfg.GenesisState[authtypes.ModuleName], &authGenState))
+	accounts, err := authtypes.UnpackAccounts(authGenState.Accounts)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, addr, accounts[0].GetAddress())
+
+	// balances of the same address should be merged
+	var bankGenState banktypes.GenesisState
+	require.NoError(t, cfg.Codec.UnmarshalJSON(cfg.GenesisState[banktypes.ModuleName], &bankGenState))
+	require.Len(t, bankGenState.Balances, 1)
+	require.True(t, bankGenState.Balances[0].Coins.Equal(coins.Add(otherCoins...)))
+	require.Len(t, bankGenState.DenomMetadata, 1)
+
+	var stakingGenState stakingtypes.GenesisState
+	require.NoError(t, cfg.Codec.UnmarshalJSON(cfg.GenesisState[stakingtypes.ModuleName], &stakingGenState))
+	require.Equal(t, stakingParams, stakingGenState.Params)
+
+	// invalid genesis should be caught by module validation
+	require.NoError(t, network.WithGenesis(&cfg, banktypes.ModuleName, func(gs *banktypes.GenesisState) {
+		gs.Supply = sdk.NewCoins(sdk.NewCoin(sdk.DefaultBondDenom, sdkmath.OneInt()))
+	}))
+	require.Error(t, cfg.BasicManager.ValidateGenesis(cfg.Codec, cfg.TxConfig, cfg.GenesisState))
+}
+
+func TestWithVoteExtensions(t *testing.T) {
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig(), network.WithVoteExtensions(5))
+	require.Equal(t, int64(5), cfg.ConsensusParams.ABCI.VoteExtensionsEnableHeight)
+	require.NoError(t, cfg.ConsensusParams.ValidateBasic())
+
+	require.Error(t, network.Configure(&cfg, network.WithVoteExtensions(0)))
+}
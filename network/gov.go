@@ -0,0 +1,238 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	abci "github.com/cometbft/cometbft/abci/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+
+	"github.com/skip-mev/chaintestutil/account"
+)
+
+const (
+	// govTxGas is the gas limit of the txs submitting and voting on proposals.
+	govTxGas = 500_000
+	// proposalPollInterval is the interval at which a proposal is queried while waiting for its result.
+	proposalPollInterval = 500 * time.Millisecond
+)
+
+// GovVotingPeriod returns a GenesisModifier setting the voting period of gov proposals, and setting the max deposit
+// period and the expedited voting period to fit within it, so that proposals pass in a few blocks.
+func GovVotingPeriod(period time.Duration) GenesisModifier {
+	// the expedited voting period must be strictly shorter than the voting period
+	expedited := period / 2
+
+	return ModuleGenesis[govv1.GenesisState](govtypes.ModuleName, func(gs *govv1.GenesisState) {
+		gs.Params.VotingPeriod = &period
+		gs.Params.ExpeditedVotingPeriod = &expedited
+		gs.Params.MaxDepositPeriod = &period
+	})
+}
+
+// ProposalResult is the outcome of a proposal submitted with SubmitAndPassProposal.
+type ProposalResult struct {
+	Proposal *govv1.Proposal
+	Status   govv1.ProposalStatus
+	// Height is the height of the block that ended the voting period of the proposal.
+	Height int64
+	// Events are the events emitted by the block that ended the voting period of the proposal, which include the
+	// events emitted by the execution of its messages.
+	Events []abci.Event
+}
+
+// SubmitAndPassProposal submits a proposal executing the messages from the targeted validator's account, votes yes
+// on it from the account of every validator and waits for the end of its voting period. The result is returned
+// along with an error if the proposal did not pass. The voting period can be shortened with GovVotingPeriod.
+func (s *TestSuite) SubmitAndPassProposal(ctx context.Context, msgs ...sdk.Msg) (*ProposalResult, error) {
+	proposalID, height, err := s.submitProposal(ctx, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.voteYes(ctx, proposalID); err != nil {
+		return nil, err
+	}
+
+	proposal, err := s.waitForProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProposalResult{
+		Proposal: proposal,
+		Status:   proposal.Status,
+	}
+
+	result.Height, result.Events, err = s.proposalEndBlock(ctx, proposalID, height)
+	if err != nil {
+		return nil, err
+	}
+
+	if proposal.Status != govv1.StatusPassed {
+		return result, fmt.Errorf("proposal %d ended with status %s: %s", proposalID, proposal.Status, proposal.FailedReason)
+	}
+
+	return result, nil
+}
+
+// submitProposal submits a proposal executing the messages from the targeted validator's account, with the
+// minimum deposit so that it directly enters its voting period, and returns its id and the height it was
+// submitted at.
+func (s *TestSuite) submitProposal(ctx context.Context, msgs ...sdk.Msg) (uint64, int64, error) {
+	params, err := s.StateAt(0).GovParams()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	acc, err := s.ValidatorAccount()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	msg, err := govv1.NewMsgSubmitProposal(msgs, params.MinDeposit, acc.Address().String(), "", "proposal", "proposal", false)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err := s.deliverTx(ctx, acc, govTxGas, msg)
+	if err != nil {
+		return 0, 0, fmt.Errorf("submitting proposal: %w", err)
+	}
+
+	for _, event := range res.Events {
+		if event.Type != govtypes.EventTypeSubmitProposal {
+			continue
+		}
+
+		for _, attr := range event.Attributes {
+			if attr.Key == govtypes.AttributeKeyProposalID {
+				proposalID, err := strconv.ParseUint(attr.Value, 10, 64)
+				return proposalID, res.Height, err
+			}
+		}
+	}
+
+	return 0, 0, fmt.Errorf("no %s event in the result of tx submitting the proposal", govtypes.EventTypeSubmitProposal)
+}
+
+// voteYes votes yes on the proposal from the account of every validator.
+func (s *TestSuite) voteYes(ctx context.Context, proposalID uint64) error {
+	for i, node := range s.Nodes() {
+		acc, err := node.ValidatorAccount()
+		if err != nil {
+			return err
+		}
+
+		msg := govv1.NewMsgVote(acc.Address(), proposalID, govv1.VoteOption_VOTE_OPTION_YES, "")
+		if _, err := node.deliverTx(ctx, acc, govTxGas, msg); err != nil {
+			return fmt.Errorf("voting on proposal %d from validator %d: %w", proposalID, i, err)
+		}
+	}
+
+	return nil
+}
+
+// waitForProposal waits until the voting period of the proposal has ended and returns it with its final status.
+func (s *TestSuite) waitForProposal(ctx context.Context, proposalID uint64) (*govv1.Proposal, error) {
+	ticker := time.NewTicker(proposalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		proposal, err := s.StateAt(0).Proposal(proposalID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch proposal.Status {
+		case govv1.StatusPassed, govv1.StatusRejected, govv1.StatusFailed:
+			return proposal, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("proposal %d still in status %s: %w", proposalID, proposal.Status, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// proposalEndBlock returns the height and the events of the block that ended the voting period of the proposal,
+// searching from the given height.
+func (s *TestSuite) proposalEndBlock(ctx context.Context, proposalID uint64, fromHeight int64) (int64, []abci.Event, error) {
+	latest, err := s.LatestBlock(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	id := strconv.FormatUint(proposalID, 10)
+	for height := fromHeight; height <= latest.Height; height++ {
+		block, err := s.Block(ctx, height)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		for _, event := range block.Events {
+			if event.Type != govtypes.EventTypeActiveProposal {
+				continue
+			}
+
+			for _, attr := range event.Attributes {
+				if attr.Key == govtypes.AttributeKeyProposalID && attr.Value == id {
+					return height, block.Events, nil
+				}
+			}
+		}
+	}
+
+	return 0, nil, fmt.Errorf("no block ended the voting period of proposal %d", proposalID)
+}
+
+// deliverTx creates a tx executing the messages signed by acc, broadcasts it to the targeted node and waits for
+// it to be committed. An error is returned if the tx failed.
+func (s *TestSuite) deliverTx(ctx context.Context, acc account.Account, gas uint64, msgs ...sdk.Msg) (*TxResult, error) {
+	fee, err := s.minFee(gas)
+	if err != nil {
+		return nil, err
+	}
+
+	bz, err := s.CreateTxBytes(ctx, TxGenInfo{Account: acc, GasLimit: gas, Fee: fee}, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	commitRes, err := s.BroadcastTxCommit(ctx, bz)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.DecodeBroadcastTxCommit(commitRes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := res.Err(); err != nil {
+		return nil, fmt.Errorf("tx %s failed: %w", res.Hash, err)
+	}
+
+	return res, nil
+}
+
+// minFee returns the minimum fee accepted by the validators for a tx with the given gas limit.
+func (s *TestSuite) minFee(gas uint64) (sdk.Coins, error) {
+	prices, err := sdk.ParseDecCoins(s.Network.Config.MinGasPrices)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := sdk.NewCoins()
+	for _, price := range prices {
+		fee = fee.Add(sdk.NewCoin(price.Denom, price.Amount.MulInt64(int64(gas)).Ceil().TruncateInt()))
+	}
+
+	return fee, nil
+}
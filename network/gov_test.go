@@ -0,0 +1,61 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/testutil/configurator"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	_ "github.com/cosmos/cosmos-sdk/x/distribution"
+	_ "github.com/cosmos/cosmos-sdk/x/gov"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestSubmitAndPassProposal(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	appConfig := configurator.NewAppConfig(
+		configurator.AuthModule(),
+		configurator.ParamsModule(),
+		configurator.BankModule(),
+		configurator.GenutilModule(),
+		configurator.StakingModule(),
+		configurator.DistributionModule(),
+		configurator.GovModule(),
+		configurator.ConsensusModule(),
+		configurator.TxModule(),
+	)
+	cfg := network.NewConfig(appConfig, network.GovVotingPeriod(5*time.Second))
+	cfg.NumValidators = 2
+	s := network.NewSuite(t, cfg)
+
+	msg := &banktypes.MsgSetSendEnabled{
+		Authority:   authtypes.NewModuleAddress(govtypes.ModuleName).String(),
+		SendEnabled: []*banktypes.SendEnabled{{Denom: "frozen", Enabled: false}},
+	}
+
+	result, err := s.SubmitAndPassProposal(ctx, msg)
+	require.NoError(t, err)
+	require.Equal(t, govv1.StatusPassed, result.Status)
+	require.NotZero(t, result.Height)
+	require.NotEmpty(t, result.Events)
+
+	// the votes are deleted once they are tallied
+	votes, err := s.StateAt(result.Height - 1).ProposalVotes(result.Proposal.Id)
+	require.NoError(t, err)
+	require.Len(t, votes, cfg.NumValidators)
+
+	// the message of the proposal was executed
+	conn, err := s.GRPC()
+	require.NoError(t, err)
+	res, err := banktypes.NewQueryClient(conn).SendEnabled(ctx, &banktypes.QuerySendEnabledRequest{Denoms: []string{"frozen"}})
+	require.NoError(t, err)
+	require.Equal(t, []*banktypes.SendEnabled{{Denom: "frozen", Enabled: false}}, res.SendEnabled)
+}
@@ -0,0 +1,554 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdkmath "cosmossdk.io/math"
+	cmttypes "github.com/cometbft/cometbft/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/skip-mev/chaintestutil/account"
+)
+
+const (
+	// defaultLoadGasLimit is the gas limit of the txs sent by RunLoad if none is configured.
+	defaultLoadGasLimit = 200_000
+	// defaultInclusionTimeout is how long RunLoad waits for the accepted txs to be included once it stopped
+	// sending txs, if no timeout is configured.
+	defaultInclusionTimeout = 30 * time.Second
+	// blockEventCapacity is how many NewBlock events RunLoad buffers while it processes a block.
+	blockEventCapacity = 100
+	// blockPollInterval is the interval at which RunLoad queries the blocks committed since the last NewBlock
+	// event, so that blocks are observed even if their events were dropped.
+	blockPollInterval = 500 * time.Millisecond
+)
+
+// LoadConfig configures the load generated by RunLoad.
+type LoadConfig struct {
+	// Accounts are the accounts sending the txs. They must exist on chain and be funded for the fees, e.g. with
+	// WithFundedAccounts. Defaults to the accounts of the suite.
+	Accounts []account.Account
+	// TPS is the target number of txs sent per second, spread across the accounts.
+	TPS float64
+	// Duration is how long txs are sent for.
+	Duration time.Duration
+	// Msgs returns the messages of the n-th tx sent by the account. Defaults to a send of 1 bond denom from the
+	// account to itself.
+	Msgs func(acc account.Account, n int) []sdk.Msg
+	// GasLimit is the gas limit of every tx. Defaults to 200,000.
+	GasLimit uint64
+	// Fee is the fee of every tx. Defaults to the minimum fee accepted by the validators for the gas limit.
+	Fee sdk.Coins
+	// InclusionTimeout is how long to wait for the accepted txs to be included once the txs stopped being sent.
+	// Defaults to 30s.
+	InclusionTimeout time.Duration
+}
+
+// ErrorCode identifies an ABCI error by its codespace and code.
+type ErrorCode struct {
+	Codespace string
+	Code      uint32
+}
+
+// LoadReport reports the behavior of the network under the load generated by RunLoad.
+type LoadReport struct {
+	// Offered is the number of txs the target TPS called for over the duration, and Skipped the number of those
+	// that were not sent because every account was still busy sending its previous tx, or failed to create it.
+	// Every offered tx is either sent or skipped. Use more accounts if txs were skipped, as the network was not
+	// offered the target TPS.
+	Offered int
+	Skipped int
+	// Sent is the number of txs broadcast, and Accepted the number of those accepted in a mempool.
+	Sent     int
+	Accepted int
+	// Included is the number of accepted txs included in a block, whether their execution succeeded or not.
+	Included int
+	// Rejected counts the txs rejected by CheckTx, by error code.
+	Rejected map[ErrorCode]int
+	// Failed counts the included txs whose execution failed, by error code.
+	Failed map[ErrorCode]int
+	// BroadcastErrors is the number of txs that could not be broadcast, e.g. because the mempool was full.
+	BroadcastErrors int
+
+	// Duration is the time between the first tx being sent and the last tx being included.
+	Duration time.Duration
+	// TPS is the number of txs included per second over Duration.
+	TPS float64
+	// Latency holds the percentiles of the time between a tx being sent and the block including it being
+	// observed on the node, by percentile, e.g. Latency[99].
+	Latency map[int]time.Duration
+
+	// Blocks are the blocks committed while the load was generated.
+	Blocks []BlockFullness
+}
+
+// BlockFullness reports how full a block is relatively to the limits of the consensus params.
+type BlockFullness struct {
+	Height int64
+	NumTxs int
+	// Bytes is the size of the txs of the block, out of MaxBytes.
+	Bytes    int64
+	MaxBytes int64
+	// GasWanted is the sum of the gas limits of the txs of the block, out of MaxGas. MaxGas is -1 if the block
+	// gas is unlimited.
+	GasWanted int64
+	GasUsed   int64
+	MaxGas    int64
+}
+
+// GasFullness returns the fraction of the max block gas wanted by the txs of the block, or 0 if the block gas is
+// unlimited.
+func (b BlockFullness) GasFullness() float64 {
+	if b.MaxGas <= 0 {
+		return 0
+	}
+
+	return float64(b.GasWanted) / float64(b.MaxGas)
+}
+
+// String summarizes the report, e.g. to log it.
+func (r *LoadReport) String() string {
+	var sb strings.Builder
+	if r.Skipped > 0 {
+		fmt.Fprintf(&sb, "skipped %d of %d offered txs as every account was busy\n", r.Skipped, r.Offered)
+	}
+	fmt.Fprintf(&sb, "sent %d, accepted %d, included %d txs in %s (%.1f TPS)\n", r.Sent, r.Accepted, r.Included,
+		r.Duration.Round(time.Millisecond), r.TPS)
+	fmt.Fprintf(&sb, "latency p50 %s, p90 %s, p99 %s\n", r.Latency[50], r.Latency[90], r.Latency[99])
+	for code, n := range r.Rejected {
+		fmt.Fprintf(&sb, "rejected %d txs with code %d (%s)\n", n, code.Code, code.Codespace)
+	}
+	for code, n := range r.Failed {
+		fmt.Fprintf(&sb, "failed %d txs with code %d (%s)\n", n, code.Code, code.Codespace)
+	}
+	if r.BroadcastErrors > 0 {
+		fmt.Fprintf(&sb, "failed to broadcast %d txs\n", r.BroadcastErrors)
+	}
+	for _, b := range r.Blocks {
+		fmt.Fprintf(&sb, "block %d: %d txs, %d/%d bytes, %d/%d gas\n", b.Height, b.NumTxs, b.Bytes, b.MaxBytes,
+			b.GasWanted, b.MaxGas)
+	}
+
+	return sb.String()
+}
+
+// loadRun holds the state of a RunLoad call shared by the senders and the block watcher.
+type loadRun struct {
+	mu     sync.Mutex
+	report *LoadReport
+	// err is the first error that stopped an account from sending txs, or that prevented a block from being
+	// observed.
+	err error
+	// sentAt holds the time every tx pending inclusion was sent at, by hash.
+	sentAt   map[string]time.Time
+	lastIncl time.Time
+	// latencies are the inclusion latencies of the included txs.
+	latencies []time.Duration
+	// allIncluded is closed once every accepted tx was included, after the txs stopped being sent.
+	sendingDone bool
+	allIncluded chan struct{}
+}
+
+// fail records the error that stopped an account from sending txs, or that prevented a block from being
+// observed.
+func (run *loadRun) fail(err error) {
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	if run.err == nil {
+		run.err = err
+	}
+}
+
+// RunLoad sends txs from the configured accounts at the target TPS for the configured duration, spreading the
+// accounts across the validators, and reports how the network handled the load. Each account tracks its own
+// sequence, so that it can have several txs pending in the mempool. The inclusion of the txs is confirmed by
+// querying the blocks committed by the targeted node.
+func (s *TestSuite) RunLoad(ctx context.Context, cfg LoadConfig) (*LoadReport, error) {
+	if cfg.TPS <= 0 || cfg.Duration <= 0 {
+		return nil, errors.New("the load TPS and duration must be positive")
+	}
+
+	if cfg.Accounts == nil {
+		cfg.Accounts = s.Accounts
+	}
+	if len(cfg.Accounts) == 0 {
+		return nil, errors.New("no accounts to generate load with")
+	}
+
+	if cfg.Msgs == nil {
+		bondDenom := s.Network.Config.BondDenom
+		cfg.Msgs = func(acc account.Account, _ int) []sdk.Msg {
+			return []sdk.Msg{banktypes.NewMsgSend(acc.Address(), acc.Address(), sdk.NewCoins(sdk.NewCoin(bondDenom, sdkmath.OneInt())))}
+		}
+	}
+
+	if cfg.GasLimit == 0 {
+		cfg.GasLimit = defaultLoadGasLimit
+	}
+
+	if cfg.Fee == nil {
+		fee, err := s.minFee(cfg.GasLimit)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Fee = fee
+	}
+
+	if cfg.InclusionTimeout == 0 {
+		cfg.InclusionTimeout = defaultInclusionTimeout
+	}
+
+	maxBytes, maxGas, err := s.blockLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &loadRun{
+		report: &LoadReport{
+			Rejected: make(map[ErrorCode]int),
+			Failed:   make(map[ErrorCode]int),
+			Latency:  make(map[int]time.Duration),
+		},
+		sentAt:      make(map[string]time.Time),
+		allIncluded: make(chan struct{}),
+	}
+
+	height, err := s.committedHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+
+	blocks, err := s.Subscribe(watchCtx, "tm.event='NewBlock'", blockEventCapacity)
+	if err != nil {
+		return nil, err
+	}
+
+	// next is the height of the next block to observe, owned by the watcher until it is done
+	next := height + 1
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		next = s.watchBlocks(watchCtx, run, next, blocks, maxBytes, maxGas)
+	}()
+
+	start := time.Now()
+	s.sendLoad(ctx, cfg, run)
+
+	run.mu.Lock()
+	run.sendingDone = true
+	if len(run.sentAt) == 0 {
+		close(run.allIncluded)
+	}
+	run.mu.Unlock()
+
+	select {
+	case <-run.allIncluded:
+	case <-time.After(cfg.InclusionTimeout):
+	case <-ctx.Done():
+	}
+
+	stopWatching()
+	<-watcherDone
+
+	// observe the blocks committed since the watcher last queried them
+	if ctx.Err() == nil {
+		s.observeBlocks(ctx, run, next, maxBytes, maxGas)
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	report := run.report
+	if report.Included > 0 {
+		report.Duration = run.lastIncl.Sub(start)
+		report.TPS = float64(report.Included) / report.Duration.Seconds()
+	}
+
+	sort.Slice(run.latencies, func(i, j int) bool { return run.latencies[i] < run.latencies[j] })
+	for _, p := range []int{50, 90, 99, 100} {
+		report.Latency[p] = percentile(run.latencies, p)
+	}
+
+	if run.err != nil {
+		return report, run.err
+	}
+
+	return report, ctx.Err()
+}
+
+// sendLoad sends the txs of the load until its duration has elapsed. Every account sends its txs in order from
+// its own goroutine, to a validator picked by the index of the account.
+func (s *TestSuite) sendLoad(ctx context.Context, cfg LoadConfig, run *loadRun) {
+	// txs are only sent until the duration has elapsed, but the txs being sent at that time are not interrupted
+	sending, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	// the account numbers never change, so the accounts are only queried again to resync their sequence. They
+	// are queried in turn, as the first concurrent queries of a store race in IAVL.
+	accIs := make([]sdk.AccountI, len(cfg.Accounts))
+	for i, acc := range cfg.Accounts {
+		accI, err := s.StateAt(0).AccountI(acc)
+		if err != nil {
+			run.fail(fmt.Errorf("querying account %s: %w", acc.Address(), err))
+			return
+		}
+		accIs[i] = accI
+	}
+
+	// ticks are only handed off to an account waiting for one, and skipped if every account is still busy
+	// sending its previous tx, so that each tick is either sent or skipped
+	ticks := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i, acc := range cfg.Accounts {
+		node := s.Node(i % len(s.Network.Validators))
+
+		wg.Add(1)
+		go func(acc account.Account, accI sdk.AccountI) {
+			defer wg.Done()
+			node.sendAccountLoad(ctx, sending, cfg, run, acc, accI, ticks)
+		}(acc, accIs[i])
+	}
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.TPS))
+	defer ticker.Stop()
+
+	var offered, skipped int
+	for done := false; !done; {
+		select {
+		case <-sending.Done():
+			done = true
+		case <-ticker.C:
+			offered++
+			select {
+			case ticks <- struct{}{}:
+			default:
+				skipped++
+			}
+		}
+	}
+
+	wg.Wait()
+
+	run.mu.Lock()
+	run.report.Offered = offered
+	run.report.Skipped += skipped
+	run.mu.Unlock()
+}
+
+// sendAccountLoad sends a tx from the account for every tick, until sending is done, starting from the queried
+// state of the account.
+func (s *TestSuite) sendAccountLoad(
+	ctx, sending context.Context,
+	cfg LoadConfig,
+	run *loadRun,
+	acc account.Account,
+	accI sdk.AccountI,
+	ticks <-chan struct{},
+) {
+	accountNumber, sequence := accI.GetAccountNumber(), accI.GetSequence()
+
+	for n := 0; ; n++ {
+		select {
+		case <-sending.Done():
+			return
+		case <-ticks:
+		}
+
+		bz, err := s.CreateTxBytes(ctx, TxGenInfo{
+			Account:               acc,
+			GasLimit:              cfg.GasLimit,
+			Fee:                   cfg.Fee,
+			OverrideSequence:      true,
+			Sequence:              sequence,
+			OverrideAccountNumber: true,
+			AccountNumber:         accountNumber,
+		}, cfg.Msgs(acc, n)...)
+		if err != nil {
+			run.mu.Lock()
+			run.report.Skipped++
+			run.mu.Unlock()
+
+			run.fail(fmt.Errorf("creating tx of account %s: %w", acc.Address(), err))
+			return
+		}
+
+		// the tx is tracked before being broadcast, as it may be included before the broadcast returns
+		hash := cmttypes.Tx(bz).Hash()
+		run.mu.Lock()
+		run.sentAt[string(hash)] = time.Now()
+		run.mu.Unlock()
+
+		res, err := s.BroadcastTx(ctx, bz, BroadcastModeSync)
+
+		run.mu.Lock()
+		run.report.Sent++
+		switch {
+		case err != nil:
+			run.report.BroadcastErrors++
+			delete(run.sentAt, string(hash))
+		case res.Code != 0:
+			run.report.Rejected[ErrorCode{Codespace: res.Codespace, Code: res.Code}]++
+			delete(run.sentAt, string(hash))
+		default:
+			run.report.Accepted++
+			sequence++
+		}
+		run.mu.Unlock()
+
+		// resync the sequence, e.g. if a pending tx of the account was evicted from the mempool
+		if err == nil && res.Code == sdkerrors.ErrWrongSequence.ABCICode() && res.Codespace == sdkerrors.RootCodespace {
+			if accI, err := s.StateAt(0).AccountI(acc); err == nil {
+				sequence = accI.GetSequence()
+			}
+		}
+	}
+}
+
+// watchBlocks observes the blocks committed by the targeted node from the given height until ctx is done, and
+// returns the height of the next block to observe. The blocks are queried when a NewBlock event is received, and
+// polled in case events were dropped or the subscription was cancelled.
+func (s *TestSuite) watchBlocks(ctx context.Context, run *loadRun, next int64, blocks *Subscription, maxBytes, maxGas int64) int64 {
+	ticker := time.NewTicker(blockPollInterval)
+	defer ticker.Stop()
+
+	events := blocks.Events()
+	for {
+		select {
+		case <-ctx.Done():
+			return next
+		case _, ok := <-events:
+			if !ok {
+				// keep polling the blocks once the subscription is cancelled
+				events = nil
+			}
+		case <-ticker.C:
+		}
+
+		next = s.observeBlocks(ctx, run, next, maxBytes, maxGas)
+	}
+}
+
+// observeBlocks observes the blocks committed by the targeted node from the given height, and returns the height
+// of the next block to observe.
+func (s *TestSuite) observeBlocks(ctx context.Context, run *loadRun, next int64, maxBytes, maxGas int64) int64 {
+	height, err := s.committedHeight()
+	if err != nil {
+		run.fail(fmt.Errorf("querying the committed height: %w", err))
+		return next
+	}
+
+	for ; next <= height; next++ {
+		block, err := s.Block(ctx, next)
+		if err != nil {
+			if ctx.Err() == nil {
+				run.fail(fmt.Errorf("querying block %d: %w", next, err))
+			}
+			return next
+		}
+
+		run.observeBlock(block, maxBytes, maxGas)
+	}
+
+	return next
+}
+
+// committedHeight returns the height of the last block committed by the targeted node, whose results are stored.
+func (s *TestSuite) committedHeight() (int64, error) {
+	stateStore, err := s.Validator().stateStore()
+	if err != nil {
+		return 0, err
+	}
+
+	state, err := stateStore.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	return state.LastBlockHeight, nil
+}
+
+// observeBlock records the inclusion of the txs of the block and its fullness.
+func (run *loadRun) observeBlock(block *Block, maxBytes, maxGas int64) {
+	observedAt := time.Now()
+
+	fullness := BlockFullness{
+		Height:   block.Height,
+		NumTxs:   len(block.Txs),
+		MaxBytes: maxBytes,
+		MaxGas:   maxGas,
+	}
+
+	run.mu.Lock()
+	defer run.mu.Unlock()
+
+	for _, tx := range block.Txs {
+		fullness.Bytes += int64(len(tx.Bytes))
+		if tx.Result != nil {
+			fullness.GasWanted += tx.Result.GasWanted
+			fullness.GasUsed += tx.Result.GasUsed
+		}
+
+		hash := string(tx.Hash)
+		sentAt, ok := run.sentAt[hash]
+		if !ok {
+			continue
+		}
+		delete(run.sentAt, hash)
+
+		run.report.Included++
+		run.latencies = append(run.latencies, observedAt.Sub(sentAt))
+		run.lastIncl = observedAt
+		if tx.Result != nil && tx.Result.Code != 0 {
+			run.report.Failed[ErrorCode{Codespace: tx.Result.Codespace, Code: tx.Result.Code}]++
+		}
+	}
+
+	run.report.Blocks = append(run.report.Blocks, fullness)
+
+	if run.sendingDone && len(run.sentAt) == 0 {
+		select {
+		case <-run.allIncluded:
+		default:
+			close(run.allIncluded)
+		}
+	}
+}
+
+// blockLimits returns the max bytes and max gas of a block from the consensus params of the targeted node.
+func (s *TestSuite) blockLimits(ctx context.Context) (maxBytes, maxGas int64, err error) {
+	cometClient, err := s.GetCometClient()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	res, err := cometClient.ConsensusParams(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return res.ConsensusParams.Block.MaxBytes, res.ConsensusParams.Block.MaxGas, nil
+}
+
+// percentile returns the p-th percentile of the sorted durations, or 0 if there are none.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(math.Ceil(float64(p)/100*float64(len(sorted)))) - 1
+	return sorted[max(i, 0)]
+}
@@ -0,0 +1,44 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestRunLoad(t *testing.T) {
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000_000))
+
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 2
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(4, coins))
+
+	report, err := s.RunLoad(context.Background(), network.LoadConfig{
+		TPS:      10,
+		Duration: 5 * time.Second,
+	})
+	require.NoError(t, err, report.String())
+
+	require.NotZero(t, report.Offered)
+	require.Equal(t, report.Offered, report.Sent+report.Skipped)
+	require.NotZero(t, report.Accepted)
+	require.Equal(t, report.Accepted, report.Included, report.String())
+	require.Empty(t, report.Failed)
+
+	// every block committed during the load is observed once
+	require.NotEmpty(t, report.Blocks)
+	var included int
+	for i, block := range report.Blocks {
+		if i > 0 {
+			require.Equal(t, report.Blocks[i-1].Height+1, block.Height)
+		}
+		included += block.NumTxs
+	}
+	require.Equal(t, report.Included, included)
+}
@@ -0,0 +1,106 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mempoolPollInterval is the interval at which the mempool is checked while waiting for it to drain.
+const mempoolPollInterval = 100 * time.Millisecond
+
+// MempoolTx is a tx pending in the mempool of a node, decoded with the network's TxConfig.
+type MempoolTx struct {
+	Bytes []byte
+	Hash  cmtbytes.HexBytes
+	// Tx is the decoded tx. It is nil if the tx could not be decoded, in which case DecodeErr is set.
+	Tx        sdk.Tx
+	DecodeErr error
+}
+
+// UnconfirmedTxs returns the txs pending in the mempool of the targeted node, in the order they would be
+// reaped by the proposer. Unlike the unconfirmed_txs RPC, the result is not capped to a single page.
+func (s *TestSuite) UnconfirmedTxs() []MempoolTx {
+	txDecoder := s.Validator().ClientCtx.TxConfig.TxDecoder()
+
+	pending := s.Validator().tmNode.Mempool().ReapMaxTxs(-1)
+
+	txs := make([]MempoolTx, len(pending))
+	for i, bz := range pending {
+		tx, err := txDecoder(bz)
+
+		txs[i] = MempoolTx{
+			Bytes:     bz,
+			Hash:      bz.Hash(),
+			Tx:        tx,
+			DecodeErr: err,
+		}
+	}
+
+	return txs
+}
+
+// NumUnconfirmedTxs returns the number of txs pending in the mempool of the targeted node.
+func (s *TestSuite) NumUnconfirmedTxs() int {
+	return s.Validator().tmNode.Mempool().Size()
+}
+
+// InMempool returns whether the tx with the given hash is pending in the mempool of the targeted node.
+func (s *TestSuite) InMempool(hash []byte) bool {
+	for _, bz := range s.Validator().tmNode.Mempool().ReapMaxTxs(-1) {
+		if bytes.Equal(bz.Hash(), hash) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequireInMempool fails the test if the tx with the given hash is not pending in the mempool of the
+// targeted node.
+func (s *TestSuite) RequireInMempool(t testing.TB, hash []byte) {
+	t.Helper()
+
+	require.True(t, s.InMempool(hash), "tx %X not in the mempool of node %d", hash, s.node)
+}
+
+// RequireNotInMempool fails the test if the tx with the given hash is pending in the mempool of the
+// targeted node.
+func (s *TestSuite) RequireNotInMempool(t testing.TB, hash []byte) {
+	t.Helper()
+
+	require.False(t, s.InMempool(hash), "tx %X in the mempool of node %d", hash, s.node)
+}
+
+// FlushMempool removes all the txs pending in the mempool of the targeted node, along with its cache of
+// seen txs. The app keeps the sequences of the removed txs in its check state until the next block is
+// committed, so the removed txs can only be submitted again after it.
+func (s *TestSuite) FlushMempool() {
+	s.Validator().tmNode.Mempool().Flush()
+}
+
+// WaitForEmptyMempool waits until the mempool of the targeted node has no pending txs, or returns an error
+// once ctx is done.
+func (s *TestSuite) WaitForEmptyMempool(ctx context.Context) error {
+	ticker := time.NewTicker(mempoolPollInterval)
+	defer ticker.Stop()
+
+	for {
+		n := s.NumUnconfirmedTxs()
+		if n == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("mempool of node %d still has %d txs: %w", s.node, n, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
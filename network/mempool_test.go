@@ -0,0 +1,69 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cmttypes "github.com/cometbft/cometbft/types"
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestMempool(t *testing.T) {
+	ctx := context.Background()
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000))
+
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 2
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(2, coins))
+	sender, recipient := s.Accounts[0], s.Accounts[1]
+
+	// the chain halts without the voting power of the second validator, so the tx stays in the mempool
+	require.NoError(t, s.Network.StopValidator(1))
+
+	msg := banktypes.NewMsgSend(sender.Address(), recipient.Address(), sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1)))
+	bz, err := s.CreateTxBytes(ctx, network.TxGenInfo{
+		Account:  sender,
+		GasLimit: 200_000,
+		Fee:      sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10)),
+	}, msg)
+	require.NoError(t, err)
+	hash := cmttypes.Tx(bz).Hash()
+
+	res, err := s.BroadcastTx(ctx, bz, network.BroadcastModeSync)
+	require.NoError(t, err)
+	require.Zero(t, res.Code, res.Log)
+
+	s.RequireInMempool(t, hash)
+	require.Equal(t, 1, s.NumUnconfirmedTxs())
+
+	txs := s.UnconfirmedTxs()
+	require.Len(t, txs, 1)
+	require.NoError(t, txs[0].DecodeErr)
+	require.Equal(t, []sdk.Msg{msg}, txs[0].Tx.GetMsgs())
+
+	s.FlushMempool()
+	s.RequireNotInMempool(t, hash)
+	require.Zero(t, s.NumUnconfirmedTxs())
+
+	require.NoError(t, s.Network.StartValidator(1))
+	height, err := s.Network.LatestHeight()
+	require.NoError(t, err)
+	_, err = s.Network.WaitForHeightWithTimeout(height+1, time.Minute)
+	require.NoError(t, err)
+
+	// the flushed tx can be submitted again once a block reset the check state of the app
+	res, err = s.BroadcastTx(ctx, bz, network.BroadcastModeSync)
+	require.NoError(t, err)
+	require.Zero(t, res.Code, res.Log)
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	require.NoError(t, s.WaitForEmptyMempool(waitCtx))
+	s.RequireNotInMempool(t, hash)
+}
@@ -3,19 +3,38 @@ package network
 
 import (
 	"fmt"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
 	"cosmossdk.io/depinject"
+	"cosmossdk.io/log"
 	pruningtypes "cosmossdk.io/store/pruning/types"
+	snapshottypes "cosmossdk.io/store/snapshots/types"
+	storetypes "cosmossdk.io/store/types"
+	upgradekeeper "cosmossdk.io/x/upgrade/keeper"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	cmttypes "github.com/cometbft/cometbft/types"
+	dbm "github.com/cosmos/cosmos-db"
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/cosmos/cosmos-sdk/crypto/hd"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/cosmos/cosmos-sdk/runtime"
+	"github.com/cosmos/cosmos-sdk/server"
 	servertypes "github.com/cosmos/cosmos-sdk/server/types"
 	"github.com/cosmos/cosmos-sdk/testutil/network"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	distrkeeper "github.com/cosmos/cosmos-sdk/x/distribution/keeper"
+	slashingkeeper "github.com/cosmos/cosmos-sdk/x/slashing/keeper"
+	stakingkeeper "github.com/cosmos/cosmos-sdk/x/staking/keeper"
 	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/sample"
 )
 
 type TestApp interface {
@@ -24,16 +43,84 @@ type TestApp interface {
 }
 
 type (
-	Network        = network.Network
-	Config         = network.Config
 	ValidatorI     = network.ValidatorI
 	AppConstructor func(val ValidatorI) TestApp
 )
 
+// Config is the configuration of the network, extending the cosmos-sdk network config.
+type Config struct {
+	network.Config
+
+	// BasicManager holds the modules of the application, used to validate the genesis state before the
+	// network is started. Validation is skipped if nil.
+	BasicManager module.BasicManager
+	// Seed is the seed the network is derived from, set by NewConfig from the CHAINTESTUTIL_SEED environment
+	// variable or drawn at random, or with WithSeed. The keys of the network are random if zero.
+	Seed int64
+	// SnapshotInterval is the interval in blocks at which the nodes take state sync snapshots, set with
+	// WithSnapshots. Snapshots are disabled if zero.
+	SnapshotInterval uint64
+	// SnapshotKeepRecent is the number of recent snapshots kept by the nodes. All the snapshots are kept if zero.
+	SnapshotKeepRecent uint32
+	// NumFullNodes is the number of non-validator full nodes of the network, set with WithFullNodes.
+	NumFullNodes int
+	// Topology holds the persistent peers of the nodes by node index, set with WithTopology. The full nodes
+	// connect to the validators, which connect to each other, if nil.
+	Topology map[int][]int
+	// ConsensusParams are the consensus params of the genesis, set with WithConsensusParams. The CometBFT
+	// defaults are used if nil.
+	ConsensusParams *cmttypes.ConsensusParams
+	// ABCIRecorder records the ABCI++ calls handled by the nodes if set, see WithABCIRecorder.
+	ABCIRecorder *ABCIRecorder
+}
+
+// ConfigOption sets a setting of the network config outside of its genesis state, e.g. the number of full nodes.
+type ConfigOption func(cfg *Config) error
+
+// ConfigModifier is either a ConfigOption or a GenesisModifier, which NewConfig both applies to the config.
+type ConfigModifier interface {
+	modify(cfg *Config) error
+}
+
+func (o ConfigOption) modify(cfg *Config) error {
+	return o(cfg)
+}
+
+func (m GenesisModifier) modify(cfg *Config) error {
+	return m(cfg)
+}
+
+// Configure applies the given config options to the config in order. See ModifyGenesis for the genesis modifiers.
+func Configure(cfg *Config, options ...ConfigOption) error {
+	for _, option := range options {
+		if err := option(cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // New creates instance with fully configured cosmos network.
 // Accepts optional config, that will be used in place of the DefaultConfig() if provided.
-func New(t *testing.T, cfg network.Config) *network.Network {
-	net, err := network.New(t, t.TempDir(), cfg)
+func New(t *testing.T, cfg Config) *Network {
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+
+		if cfg.Seed == 0 {
+			t.Log("network unseeded, build its config with NewConfig or WithSeed to reproduce it")
+		} else {
+			t.Logf("network seed: %d, rerun with %s=%d to reproduce the network", cfg.Seed, sample.SeedEnv, cfg.Seed)
+		}
+	})
+
+	if cfg.BasicManager != nil {
+		require.NoError(t, cfg.BasicManager.ValidateGenesis(cfg.Codec, cfg.TxConfig, cfg.GenesisState))
+	}
+
+	net, err := newNetwork(t, t.TempDir(), cfg)
 	require.NoError(t, err)
 	t.Cleanup(net.Cleanup)
 	return net
@@ -41,12 +128,27 @@ func New(t *testing.T, cfg network.Config) *network.Network {
 
 // NewConfig will initialize config for the network with custom application,
 // genesis and single validator. All other parameters are inherited from cosmos-sdk/testutil/network.DefaultConfig
-func NewConfig(appConfig depinject.Config) network.Config {
+// The config options and genesis modifiers are applied to the resulting config in order, e.g. WithFullNodes or
+// GovVotingPeriod. The config is seeded with the CHAINTESTUTIL_SEED environment variable if it is set, or with a
+// random seed otherwise, which is logged if the test fails. WithSeed overrides it.
+func NewConfig(appConfig depinject.Config, modifiers ...ConfigModifier) Config {
 	cfg, err := network.DefaultConfigWithAppConfig(appConfig)
 	if err != nil {
 		panic(err)
 	}
 
+	var basicManager module.BasicManager
+	if err := depinject.Inject(
+		depinject.Configs(
+			appConfig,
+			depinject.Supply(log.NewNopLogger()),
+		),
+		&basicManager,
+	); err != nil {
+		panic(err)
+	}
+
+	cfg.AppConstructor = NewAppConstructor(appConfig, "")
 	cfg.AccountRetriever = authtypes.AccountRetriever{}
 	cfg.TimeoutCommit = 2 * time.Second
 	cfg.NumValidators = 1
@@ -60,5 +162,294 @@ func NewConfig(appConfig depinject.Config) network.Config {
 	cfg.SigningAlgo = string(hd.Secp256k1Type)
 	cfg.KeyringOptions = []keyring.Option{}
 
-	return cfg
+	config := Config{
+		Config:       cfg,
+		BasicManager: basicManager,
+	}
+	// a seed set with WithSeed takes precedence over the seed of the environment
+	modifiers = append([]ConfigModifier{WithSeed(sample.Seed())}, modifiers...)
+
+	for _, modifier := range modifiers {
+		if err := modifier.modify(&config); err != nil {
+			panic(err)
+		}
+	}
+
+	return config
+}
+
+// AppOption represents an option that can be provided to NewAppConstructor.
+type AppOption func(*AppOptions)
+
+// AppOptions represents the options used to build the app of each validator.
+type AppOptions struct {
+	// UpgradeHandlers are registered on the upgrade keeper of the app, by upgrade name.
+	UpgradeHandlers map[string]upgradetypes.UpgradeHandler
+	// StoreUpgrades are applied when the app is started for the upgrade of the same name.
+	StoreUpgrades map[string]storetypes.StoreUpgrades
+	// SnapshotExtensions build the extensions registered on the snapshot manager of the app, see
+	// WithSnapshotExtension.
+	SnapshotExtensions []any
+	// BaseAppOptions are applied to the BaseApp of the app when it is built.
+	BaseAppOptions []func(*baseapp.BaseApp)
+	// OnDiskDB stores the state of the app in the data dir of the validator instead of in memory, see
+	// WithOnDiskDB.
+	OnDiskDB bool
+}
+
+// WithOnDiskDB stores the state of the app in the data dir of the validator, so that a new app built for the
+// validator once its node is stopped loads the state of the previous one. It is required to export the state
+// with ExportAndRestart and to swap the app for a chain upgrade with Upgrade. An app storing its state in memory
+// starts empty and replays the blocks of its node instead.
+func WithOnDiskDB() AppOption {
+	return func(options *AppOptions) {
+		options.OnDiskDB = true
+	}
+}
+
+// WithUpgradeHandler registers the handler of the named upgrade on the upgrade keeper of the app.
+func WithUpgradeHandler(name string, handler upgradetypes.UpgradeHandler) AppOption {
+	return func(options *AppOptions) {
+		if options.UpgradeHandlers == nil {
+			options.UpgradeHandlers = make(map[string]upgradetypes.UpgradeHandler)
+		}
+		options.UpgradeHandlers[name] = handler
+	}
+}
+
+// WithStoreUpgrades adds, renames or deletes stores when the app is started for the named upgrade.
+func WithStoreUpgrades(name string, upgrades storetypes.StoreUpgrades) AppOption {
+	return func(options *AppOptions) {
+		if options.StoreUpgrades == nil {
+			options.StoreUpgrades = make(map[string]storetypes.StoreUpgrades)
+		}
+		options.StoreUpgrades[name] = upgrades
+	}
+}
+
+// WithBaseAppOptions applies the options to the BaseApp of the app when it is built, e.g. to set the ABCI++
+// handlers of the app:
+//
+//	network.WithBaseAppOptions(func(app *baseapp.BaseApp) {
+//		app.SetExtendVoteHandler(extendVote)
+//		app.SetVerifyVoteExtensionHandler(verifyVoteExtension)
+//	})
+func WithBaseAppOptions(baseAppOptions ...func(*baseapp.BaseApp)) AppOption {
+	return func(options *AppOptions) {
+		options.BaseAppOptions = append(options.BaseAppOptions, baseAppOptions...)
+	}
+}
+
+// WithSnapshotExtension registers the extension of the state sync snapshots built by newExtension on the
+// snapshot manager of the app, e.g. for a module storing state outside of the multistore. newExtension is a
+// function taking the app and any number of dependencies injected from the app config, such as keepers, and
+// returning a snapshottypes.ExtensionSnapshotter. The extension is only registered if snapshots are enabled, see
+// WithSnapshots.
+//
+//	network.WithSnapshotExtension(func(app *runtime.App, k *keeper.Keeper) snapshottypes.ExtensionSnapshotter {
+//		return keeper.NewSnapshotter(app.CommitMultiStore(), k)
+//	})
+func WithSnapshotExtension(newExtension any) AppOption {
+	return func(options *AppOptions) {
+		options.SnapshotExtensions = append(options.SnapshotExtensions, newExtension)
+	}
+}
+
+// NewAppConstructor returns an app constructor building the app described by appConfig for every validator.
+// The state of the app is stored in memory, unless WithOnDiskDB is set. The app uses the chain ID of the network
+// if chainID is empty. The app takes state sync snapshots if they are enabled in the app config of the validator. It implements
+// ExportableApp and InvariantsApp.
+func NewAppConstructor(appConfig depinject.Config, chainID string, options ...AppOption) network.AppConstructor {
+	var ao AppOptions
+	for _, option := range options {
+		option(&ao)
+	}
+
+	return func(val ValidatorI) servertypes.Application {
+		var (
+			appBuilder    *runtime.AppBuilder
+			upgradeKeeper *upgradekeeper.Keeper
+			exportable    exportableApp
+		)
+
+		outputs := []interface{}{&appBuilder}
+		if len(ao.UpgradeHandlers) > 0 || len(ao.StoreUpgrades) > 0 {
+			outputs = append(outputs, &upgradeKeeper)
+		}
+
+		extensions := make([]snapshotExtension, len(ao.SnapshotExtensions))
+		for i, newExtension := range ao.SnapshotExtensions {
+			var err error
+			if extensions[i], err = newSnapshotExtension(newExtension); err != nil {
+				panic(err)
+			}
+			outputs = append(outputs, extensions[i].outputs()...)
+		}
+
+		if err := depinject.Inject(
+			depinject.Configs(
+				appConfig,
+				depinject.Supply(val.GetCtx().Logger),
+				depinject.Provide(func() servertypes.AppOptions { return val.GetCtx().Viper }),
+				// the keepers used by the export are missing from apps without their modules
+				depinject.Invoke(func(
+					cdc codec.Codec,
+					stakingKeeper *stakingkeeper.Keeper,
+					distrKeeper distrkeeper.Keeper,
+					slashingKeeper slashingkeeper.Keeper,
+				) {
+					exportable.cdc = cdc
+					exportable.stakingKeeper = stakingKeeper
+					exportable.distrKeeper = distrKeeper
+					exportable.slashingKeeper = slashingKeeper
+				}),
+			),
+			outputs...,
+		); err != nil {
+			panic(err)
+		}
+
+		appChainID := chainID
+		if appChainID == "" {
+			appChainID = val.GetCtx().Viper.GetString(flags.FlagChainID)
+		}
+
+		var db dbm.DB = dbm.NewMemDB()
+		if ao.OnDiskDB {
+			goLevelDB, err := dbm.NewGoLevelDB("application", filepath.Join(val.GetCtx().Config.RootDir, "data"), nil)
+			if err != nil {
+				panic(err)
+			}
+			db = emptyValueDB{goLevelDB}
+		}
+
+		baseAppOptions := []func(*baseapp.BaseApp){
+			baseapp.SetPruning(pruningtypes.NewPruningOptionsFromString(val.GetAppConfig().Pruning)),
+			baseapp.SetMinGasPrices(val.GetAppConfig().MinGasPrices),
+			baseapp.SetChainID(appChainID),
+		}
+
+		if stateSync := val.GetAppConfig().StateSync; stateSync.SnapshotInterval > 0 {
+			// the snapshots are stored in the data dir of the validator, read from the home flag
+			snapshotStore, err := server.GetSnapshotStore(val.GetCtx().Viper)
+			if err != nil {
+				panic(err)
+			}
+
+			baseAppOptions = append(baseAppOptions, baseapp.SetSnapshot(
+				snapshotStore,
+				snapshottypes.NewSnapshotOptions(stateSync.SnapshotInterval, stateSync.SnapshotKeepRecent),
+			))
+		}
+
+		app := appBuilder.Build(db, nil, append(baseAppOptions, ao.BaseAppOptions...)...)
+
+		if app.SnapshotManager() != nil {
+			for _, extension := range extensions {
+				if err := app.SnapshotManager().RegisterExtensions(extension.build(app)); err != nil {
+					panic(err)
+				}
+			}
+		}
+
+		if upgradeKeeper != nil {
+			for name, handler := range ao.UpgradeHandlers {
+				upgradeKeeper.SetUpgradeHandler(name, handler)
+			}
+
+			// the upgrade info is written by the previous app when the chain halts for an upgrade
+			upgradeInfo, err := upgradeKeeper.ReadUpgradeInfoFromDisk()
+			if err != nil {
+				panic(err)
+			}
+
+			if upgrades, ok := ao.StoreUpgrades[upgradeInfo.Name]; ok {
+				app.SetStoreLoader(upgradetypes.UpgradeStoreLoader(upgradeInfo.Height, &upgrades))
+			}
+		}
+
+		if err := app.Load(true); err != nil {
+			panic(err)
+		}
+
+		exportable.App = app
+		exportable.inMemory = !ao.OnDiskDB
+		return &exportable
+	}
+}
+
+// snapshotExtension calls the function building a snapshot extension with the dependencies injected into its
+// args.
+type snapshotExtension struct {
+	fn   reflect.Value
+	args []reflect.Value
+}
+
+var (
+	appType                  = reflect.TypeOf((*runtime.App)(nil))
+	extensionSnapshotterType = reflect.TypeOf((*snapshottypes.ExtensionSnapshotter)(nil)).Elem()
+)
+
+// newSnapshotExtension checks the signature of the function building a snapshot extension, see
+// WithSnapshotExtension.
+func newSnapshotExtension(newExtension any) (snapshotExtension, error) {
+	fn := reflect.ValueOf(newExtension)
+	typ := fn.Type()
+	if typ.Kind() != reflect.Func || typ.NumIn() == 0 || typ.In(0) != appType ||
+		typ.NumOut() != 1 || typ.Out(0) != extensionSnapshotterType {
+		return snapshotExtension{}, fmt.Errorf(
+			"snapshot extension must be built by a func(*runtime.App, ...) snapshottypes.ExtensionSnapshotter, got %s", typ)
+	}
+
+	extension := snapshotExtension{fn: fn}
+	for i := 1; i < typ.NumIn(); i++ {
+		extension.args = append(extension.args, reflect.New(typ.In(i)))
+	}
+
+	return extension, nil
+}
+
+// outputs returns the pointers to the dependencies of the extension, into which they are injected.
+func (e snapshotExtension) outputs() []interface{} {
+	outputs := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		outputs[i] = arg.Interface()
+	}
+
+	return outputs
+}
+
+// build returns the extension built for the app with the injected dependencies.
+func (e snapshotExtension) build(app *runtime.App) snapshottypes.ExtensionSnapshotter {
+	args := []reflect.Value{reflect.ValueOf(app)}
+	for _, arg := range e.args {
+		args = append(args, arg.Elem())
+	}
+
+	return e.fn.Call(args)[0].Interface().(snapshottypes.ExtensionSnapshotter)
+}
+
+// emptyValueDB works around goleveldb returning nil for keys set to an empty value. IAVL stores the root of an
+// empty tree as an empty value, so that the versions of the stores that are empty would read as missing and every
+// query of the app would fail, as TestExportAndRestart shows without it.
+type emptyValueDB struct {
+	*dbm.GoLevelDB
+}
+
+func (db emptyValueDB) Get(key []byte) ([]byte, error) {
+	value, err := db.GoLevelDB.Get(key)
+	if value != nil || err != nil {
+		return value, err
+	}
+
+	has, err := db.Has(key)
+	if err != nil || !has {
+		return nil, err
+	}
+
+	return []byte{}, nil
+}
+
+func (db emptyValueDB) Has(key []byte) (bool, error) {
+	return db.DB().Has(key, nil)
 }
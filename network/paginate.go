@@ -0,0 +1,92 @@
+package network
+
+import (
+	"context"
+
+	sdkquery "github.com/cosmos/cosmos-sdk/types/query"
+	"google.golang.org/grpc"
+)
+
+// PaginatedResponse is implemented by the responses of list-style queries.
+type PaginatedResponse interface {
+	GetPagination() *sdkquery.PageResponse
+}
+
+// PaginateOption represents an option that can be provided to Paginate.
+type PaginateOption func(*PaginateOptions)
+
+// PaginateOptions represents the options used to page through the results of a query.
+type PaginateOptions struct {
+	// PageSize is the number of items requested per page. The default page size of the queried module is
+	// used if zero.
+	PageSize uint64
+	// Reverse requests the items in descending order.
+	Reverse bool
+}
+
+// WithPageSize sets the number of items requested per page.
+func WithPageSize(pageSize uint64) PaginateOption {
+	return func(options *PaginateOptions) {
+		options.PageSize = pageSize
+	}
+}
+
+// WithReverse requests the items in descending order.
+func WithReverse() PaginateOption {
+	return func(options *PaginateOptions) {
+		options.Reverse = true
+	}
+}
+
+// Paginate calls query with the request built by newReq for each page, following the next key of each
+// response until all pages have been read, and returns the responses of all pages.
+//
+//	resps, err := network.Paginate(ctx, bankClient.AllBalances,
+//		func(pageReq *query.PageRequest) *banktypes.QueryAllBalancesRequest {
+//			return &banktypes.QueryAllBalancesRequest{Address: addr, Pagination: pageReq}
+//		},
+//	)
+func Paginate[Req any, Resp PaginatedResponse](
+	ctx context.Context,
+	query func(ctx context.Context, req Req, opts ...grpc.CallOption) (Resp, error),
+	newReq func(pageReq *sdkquery.PageRequest) Req,
+	options ...PaginateOption,
+) ([]Resp, error) {
+	var po PaginateOptions
+	for _, option := range options {
+		option(&po)
+	}
+
+	var (
+		resps   []Resp
+		nextKey []byte
+	)
+
+	for {
+		resp, err := query(ctx, newReq(&sdkquery.PageRequest{
+			Key:     nextKey,
+			Limit:   po.PageSize,
+			Reverse: po.Reverse,
+		}))
+		if err != nil {
+			return nil, err
+		}
+		resps = append(resps, resp)
+
+		pageResp := resp.GetPagination()
+		if pageResp == nil || len(pageResp.NextKey) == 0 {
+			return resps, nil
+		}
+		nextKey = pageResp.NextKey
+	}
+}
+
+// flatten returns the items of all the given pages.
+func flatten[Resp any, S ~[]T, T any](resps []Resp, items func(Resp) S) S {
+	var all S
+	for _, resp := range resps {
+		all = append(all, items(resp)...)
+	}
+
+	return all
+}
@@ -0,0 +1,93 @@
+package network_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	sdkquery "github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestPaginate(t *testing.T) {
+	const numItems = 25
+
+	// query mocks a list-style query over numItems denoms, using the index of the next item as key
+	var pageReqs []*sdkquery.PageRequest
+	query := func(_ context.Context, req *banktypes.QueryDenomsMetadataRequest, _ ...grpc.CallOption) (*banktypes.QueryDenomsMetadataResponse, error) {
+		pageReqs = append(pageReqs, req.Pagination)
+
+		start := 0
+		if len(req.Pagination.Key) > 0 {
+			var err error
+			if start, err = strconv.Atoi(string(req.Pagination.Key)); err != nil {
+				return nil, err
+			}
+		}
+
+		limit := int(req.Pagination.Limit)
+		if limit == 0 {
+			limit = 10
+		}
+		end := min(start+limit, numItems)
+
+		resp := &banktypes.QueryDenomsMetadataResponse{Pagination: &sdkquery.PageResponse{}}
+		for i := start; i < end; i++ {
+			resp.Metadatas = append(resp.Metadatas, banktypes.Metadata{Base: strconv.Itoa(i)})
+		}
+		if end < numItems {
+			resp.Pagination.NextKey = []byte(strconv.Itoa(end))
+		}
+
+		return resp, nil
+	}
+	newReq := func(pageReq *sdkquery.PageRequest) *banktypes.QueryDenomsMetadataRequest {
+		return &banktypes.QueryDenomsMetadataRequest{Pagination: pageReq}
+	}
+
+	t.Run("follows next key until exhaustion", func(t *testing.T) {
+		pageReqs = nil
+
+		resps, err := network.Paginate(context.Background(), query, newReq)
+		require.NoError(t, err)
+		require.Len(t, resps, 3)
+		require.Len(t, pageReqs, 3)
+		require.Nil(t, pageReqs[0].Key)
+		require.Equal(t, []byte("10"), pageReqs[1].Key)
+		require.Equal(t, []byte("20"), pageReqs[2].Key)
+
+		var count int
+		for _, resp := range resps {
+			count += len(resp.Metadatas)
+		}
+		require.Equal(t, numItems, count)
+	})
+
+	t.Run("applies options to every page", func(t *testing.T) {
+		pageReqs = nil
+
+		resps, err := network.Paginate(context.Background(), query, newReq,
+			network.WithPageSize(5), network.WithReverse())
+		require.NoError(t, err)
+		require.Len(t, resps, 5)
+		for _, pageReq := range pageReqs {
+			require.Equal(t, uint64(5), pageReq.Limit)
+			require.True(t, pageReq.Reverse)
+		}
+	})
+
+	t.Run("returns query errors", func(t *testing.T) {
+		expectedErr := errors.New("query failed")
+		failing := func(context.Context, *banktypes.QueryDenomsMetadataRequest, ...grpc.CallOption) (*banktypes.QueryDenomsMetadataResponse, error) {
+			return nil, expectedErr
+		}
+
+		_, err := network.Paginate(context.Background(), failing, newReq)
+		require.ErrorIs(t, err, expectedErr)
+	})
+}
@@ -1,110 +1,459 @@
 package network
 
 import (
-	"context"
-
+	sdkmath "cosmossdk.io/math"
+	"cosmossdk.io/x/feegrant"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkquery "github.com/cosmos/cosmos-sdk/types/query"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
 	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	minttypes "github.com/cosmos/cosmos-sdk/x/mint/types"
+	slashingtypes "github.com/cosmos/cosmos-sdk/x/slashing/types"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 
 	"github.com/skip-mev/chaintestutil/account"
 )
 
 func (s *TestSuite) AccountI(acc account.Account) (sdk.AccountI, error) {
-	cc, closeFn, err := s.GetGRPC()
+	cc, err := s.GRPC()
 	if err != nil {
 		return nil, err
 	}
-	defer closeFn()
 
 	authClient := authtypes.NewQueryClient(cc)
 
-	resp, err := authClient.Account(context.Background(), &authtypes.QueryAccountRequest{Address: acc.Address().String()})
+	resp, err := authClient.Account(s.queryContext(), &authtypes.QueryAccountRequest{Address: acc.Address().String()})
 	if err != nil {
 		return nil, err
 	}
 
 	var accI sdk.AccountI
-	if err := cdc.UnpackAny(resp.Account, &accI); err != nil {
+	if err := s.Network.Config.InterfaceRegistry.UnpackAny(resp.Account, &accI); err != nil {
 		return nil, err
 	}
 
 	return accI, err
 }
 
-func (s *TestSuite) Balances(acc account.Account) (sdk.Coins, error) {
-	cc, closeFn, err := s.GetGRPC()
+func (s *TestSuite) Balances(acc account.Account, options ...PaginateOption) (sdk.Coins, error) {
+	cc, err := s.GRPC()
 	if err != nil {
 		return nil, err
 	}
-	defer closeFn()
 
 	bankClient := banktypes.NewQueryClient(cc)
 
-	resp, err := bankClient.AllBalances(context.Background(), &banktypes.QueryAllBalancesRequest{
-		Address:      acc.Address().String(),
-		Pagination:   nil,
-		ResolveDenom: false,
-	})
+	resps, err := Paginate(s.queryContext(), bankClient.AllBalances,
+		func(pageReq *sdkquery.PageRequest) *banktypes.QueryAllBalancesRequest {
+			return &banktypes.QueryAllBalancesRequest{
+				Address:      acc.Address().String(),
+				Pagination:   pageReq,
+				ResolveDenom: false,
+			}
+		}, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	return resp.Balances, nil
+	return sdk.NewCoins(flatten(resps, (*banktypes.QueryAllBalancesResponse).GetBalances)...), nil
 }
 
-func (s *TestSuite) AllValidators() ([]stakingtypes.Validator, error) {
-	cc, closeFn, err := s.GetGRPC()
+func (s *TestSuite) AllValidators(options ...PaginateOption) ([]stakingtypes.Validator, error) {
+	cc, err := s.GRPC()
 	if err != nil {
 		return nil, err
 	}
-	defer closeFn()
 
 	stakingClient := stakingtypes.NewQueryClient(cc)
 
-	resp, err := stakingClient.Validators(context.Background(), &stakingtypes.QueryValidatorsRequest{
-		Status:     "",
-		Pagination: nil,
-	})
+	resps, err := Paginate(s.queryContext(), stakingClient.Validators,
+		func(pageReq *sdkquery.PageRequest) *stakingtypes.QueryValidatorsRequest {
+			return &stakingtypes.QueryValidatorsRequest{
+				Status:     "",
+				Pagination: pageReq,
+			}
+		}, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	return resp.Validators, nil
+	return flatten(resps, (*stakingtypes.QueryValidatorsResponse).GetValidators), nil
 }
 
-func (s *TestSuite) ValidatorDelegations(valAddr string) ([]stakingtypes.DelegationResponse, error) {
-	cc, closeFn, err := s.GetGRPC()
+func (s *TestSuite) ValidatorDelegations(valAddr string, options ...PaginateOption) ([]stakingtypes.DelegationResponse, error) {
+	cc, err := s.GRPC()
 	if err != nil {
 		return nil, err
 	}
-	defer closeFn()
 
 	stakingClient := stakingtypes.NewQueryClient(cc)
 
-	resp, err := stakingClient.ValidatorDelegations(context.Background(), &stakingtypes.QueryValidatorDelegationsRequest{
-		ValidatorAddr: valAddr,
-		Pagination:    nil,
-	})
+	resps, err := Paginate(s.queryContext(), stakingClient.ValidatorDelegations,
+		func(pageReq *sdkquery.PageRequest) *stakingtypes.QueryValidatorDelegationsRequest {
+			return &stakingtypes.QueryValidatorDelegationsRequest{
+				ValidatorAddr: valAddr,
+				Pagination:    pageReq,
+			}
+		}, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	return resp.DelegationResponses, nil
+	return flatten(resps, (*stakingtypes.QueryValidatorDelegationsResponse).GetDelegationResponses), nil
 }
 
 func (s *TestSuite) ValidatorDistributionInfo(valAddr string) (*distrtypes.QueryValidatorDistributionInfoResponse, error) {
-	cc, closeFn, err := s.GetGRPC()
+	cc, err := s.GRPC()
 	if err != nil {
 		return nil, err
 	}
-	defer closeFn()
 
 	distrClient := distrtypes.NewQueryClient(cc)
 
-	return distrClient.ValidatorDistributionInfo(context.Background(), &distrtypes.QueryValidatorDistributionInfoRequest{
+	return distrClient.ValidatorDistributionInfo(s.queryContext(), &distrtypes.QueryValidatorDistributionInfoRequest{
 		ValidatorAddress: valAddr,
 	})
 }
+
+// Proposals returns all the gov proposals.
+func (s *TestSuite) Proposals(options ...PaginateOption) ([]*govv1.Proposal, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	govClient := govv1.NewQueryClient(cc)
+
+	resps, err := Paginate(s.queryContext(), govClient.Proposals,
+		func(pageReq *sdkquery.PageRequest) *govv1.QueryProposalsRequest {
+			return &govv1.QueryProposalsRequest{
+				Pagination: pageReq,
+			}
+		}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return flatten(resps, (*govv1.QueryProposalsResponse).GetProposals), nil
+}
+
+// Proposal returns the gov proposal with the given id.
+func (s *TestSuite) Proposal(proposalID uint64) (*govv1.Proposal, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	govClient := govv1.NewQueryClient(cc)
+
+	resp, err := govClient.Proposal(s.queryContext(), &govv1.QueryProposalRequest{
+		ProposalId: proposalID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Proposal, nil
+}
+
+// ProposalVotes returns all the votes of the gov proposal with the given id.
+func (s *TestSuite) ProposalVotes(proposalID uint64, options ...PaginateOption) ([]*govv1.Vote, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	govClient := govv1.NewQueryClient(cc)
+
+	resps, err := Paginate(s.queryContext(), govClient.Votes,
+		func(pageReq *sdkquery.PageRequest) *govv1.QueryVotesRequest {
+			return &govv1.QueryVotesRequest{
+				ProposalId: proposalID,
+				Pagination: pageReq,
+			}
+		}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return flatten(resps, (*govv1.QueryVotesResponse).GetVotes), nil
+}
+
+// GovParams returns the parameters of the gov module.
+func (s *TestSuite) GovParams() (*govv1.Params, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	govClient := govv1.NewQueryClient(cc)
+
+	resp, err := govClient.Params(s.queryContext(), &govv1.QueryParamsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Params, nil
+}
+
+// FeeAllowances returns all the feegrant allowances granted to the grantee, with their allowances unpacked.
+func (s *TestSuite) FeeAllowances(grantee string, options ...PaginateOption) ([]*feegrant.Grant, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	feegrantClient := feegrant.NewQueryClient(cc)
+
+	resps, err := Paginate(s.queryContext(), feegrantClient.Allowances,
+		func(pageReq *sdkquery.PageRequest) *feegrant.QueryAllowancesRequest {
+			return &feegrant.QueryAllowancesRequest{
+				Grantee:    grantee,
+				Pagination: pageReq,
+			}
+		}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := flatten(resps, (*feegrant.QueryAllowancesResponse).GetAllowances)
+	for _, grant := range grants {
+		if err := grant.UnpackInterfaces(s.Network.Config.InterfaceRegistry); err != nil {
+			return nil, err
+		}
+	}
+
+	return grants, nil
+}
+
+// FeeAllowance returns the feegrant allowance granted by the granter to the grantee, with its allowance unpacked.
+func (s *TestSuite) FeeAllowance(granter, grantee string) (*feegrant.Grant, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	feegrantClient := feegrant.NewQueryClient(cc)
+
+	resp, err := feegrantClient.Allowance(s.queryContext(), &feegrant.QueryAllowanceRequest{
+		Granter: granter,
+		Grantee: grantee,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resp.Allowance.UnpackInterfaces(s.Network.Config.InterfaceRegistry); err != nil {
+		return nil, err
+	}
+
+	return resp.Allowance, nil
+}
+
+// AuthzGrants returns all the authz grants from the granter to the grantee, with their authorizations unpacked.
+// If msgTypeURL is not empty, only the grants for that message type are returned.
+func (s *TestSuite) AuthzGrants(granter, grantee, msgTypeURL string, options ...PaginateOption) ([]*authz.Grant, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	authzClient := authz.NewQueryClient(cc)
+
+	resps, err := Paginate(s.queryContext(), authzClient.Grants,
+		func(pageReq *sdkquery.PageRequest) *authz.QueryGrantsRequest {
+			return &authz.QueryGrantsRequest{
+				Granter:    granter,
+				Grantee:    grantee,
+				MsgTypeUrl: msgTypeURL,
+				Pagination: pageReq,
+			}
+		}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	grants := flatten(resps, (*authz.QueryGrantsResponse).GetGrants)
+	for _, grant := range grants {
+		if err := grant.UnpackInterfaces(s.Network.Config.InterfaceRegistry); err != nil {
+			return nil, err
+		}
+	}
+
+	return grants, nil
+}
+
+// SigningInfos returns the slashing signing infos of all validators.
+func (s *TestSuite) SigningInfos(options ...PaginateOption) ([]slashingtypes.ValidatorSigningInfo, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	slashingClient := slashingtypes.NewQueryClient(cc)
+
+	resps, err := Paginate(s.queryContext(), slashingClient.SigningInfos,
+		func(pageReq *sdkquery.PageRequest) *slashingtypes.QuerySigningInfosRequest {
+			return &slashingtypes.QuerySigningInfosRequest{
+				Pagination: pageReq,
+			}
+		}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return flatten(resps, (*slashingtypes.QuerySigningInfosResponse).GetInfo), nil
+}
+
+// SigningInfo returns the slashing signing info of the validator with the given consensus address.
+func (s *TestSuite) SigningInfo(consAddr string) (slashingtypes.ValidatorSigningInfo, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return slashingtypes.ValidatorSigningInfo{}, err
+	}
+
+	slashingClient := slashingtypes.NewQueryClient(cc)
+
+	resp, err := slashingClient.SigningInfo(s.queryContext(), &slashingtypes.QuerySigningInfoRequest{
+		ConsAddress: consAddr,
+	})
+	if err != nil {
+		return slashingtypes.ValidatorSigningInfo{}, err
+	}
+
+	return resp.ValSigningInfo, nil
+}
+
+// Inflation returns the current mint inflation rate.
+func (s *TestSuite) Inflation() (sdkmath.LegacyDec, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return sdkmath.LegacyDec{}, err
+	}
+
+	mintClient := minttypes.NewQueryClient(cc)
+
+	resp, err := mintClient.Inflation(s.queryContext(), &minttypes.QueryInflationRequest{})
+	if err != nil {
+		return sdkmath.LegacyDec{}, err
+	}
+
+	return resp.Inflation, nil
+}
+
+// UpgradePlan returns the currently scheduled upgrade plan, or nil if there is none.
+func (s *TestSuite) UpgradePlan() (*upgradetypes.Plan, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	upgradeClient := upgradetypes.NewQueryClient(cc)
+
+	resp, err := upgradeClient.CurrentPlan(s.queryContext(), &upgradetypes.QueryCurrentPlanRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Plan, nil
+}
+
+// TotalSupply returns the total supply of all denoms.
+func (s *TestSuite) TotalSupply(options ...PaginateOption) (sdk.Coins, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	bankClient := banktypes.NewQueryClient(cc)
+
+	resps, err := Paginate(s.queryContext(), bankClient.TotalSupply,
+		func(pageReq *sdkquery.PageRequest) *banktypes.QueryTotalSupplyRequest {
+			return &banktypes.QueryTotalSupplyRequest{
+				Pagination: pageReq,
+			}
+		}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdk.NewCoins(flatten(resps, (*banktypes.QueryTotalSupplyResponse).GetSupply)...), nil
+}
+
+// SupplyOf returns the total supply of the given denom.
+func (s *TestSuite) SupplyOf(denom string) (sdk.Coin, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return sdk.Coin{}, err
+	}
+
+	bankClient := banktypes.NewQueryClient(cc)
+
+	resp, err := bankClient.SupplyOf(s.queryContext(), &banktypes.QuerySupplyOfRequest{
+		Denom: denom,
+	})
+	if err != nil {
+		return sdk.Coin{}, err
+	}
+
+	return resp.Amount, nil
+}
+
+// DenomsMetadata returns the metadata of all denoms.
+func (s *TestSuite) DenomsMetadata(options ...PaginateOption) ([]banktypes.Metadata, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	bankClient := banktypes.NewQueryClient(cc)
+
+	resps, err := Paginate(s.queryContext(), bankClient.DenomsMetadata,
+		func(pageReq *sdkquery.PageRequest) *banktypes.QueryDenomsMetadataRequest {
+			return &banktypes.QueryDenomsMetadataRequest{
+				Pagination: pageReq,
+			}
+		}, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return flatten(resps, (*banktypes.QueryDenomsMetadataResponse).GetMetadatas), nil
+}
+
+// DenomMetadata returns the metadata of the given denom.
+func (s *TestSuite) DenomMetadata(denom string) (banktypes.Metadata, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return banktypes.Metadata{}, err
+	}
+
+	bankClient := banktypes.NewQueryClient(cc)
+
+	resp, err := bankClient.DenomMetadata(s.queryContext(), &banktypes.QueryDenomMetadataRequest{
+		Denom: denom,
+	})
+	if err != nil {
+		return banktypes.Metadata{}, err
+	}
+
+	return resp.Metadata, nil
+}
+
+// DelegatorRewards returns the rewards of the delegator for each of its delegations, along with their total.
+func (s *TestSuite) DelegatorRewards(delAddr string) (*distrtypes.QueryDelegationTotalRewardsResponse, error) {
+	cc, err := s.GRPC()
+	if err != nil {
+		return nil, err
+	}
+
+	distrClient := distrtypes.NewQueryClient(cc)
+
+	return distrClient.DelegationTotalRewards(s.queryContext(), &distrtypes.QueryDelegationTotalRewardsRequest{
+		DelegatorAddress: delAddr,
+	})
+}
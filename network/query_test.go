@@ -0,0 +1,254 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appv1alpha1 "cosmossdk.io/api/cosmos/app/v1alpha1"
+	upgrademodulev1 "cosmossdk.io/api/cosmos/upgrade/module/v1"
+	"cosmossdk.io/core/appconfig"
+	"cosmossdk.io/depinject"
+	"cosmossdk.io/x/feegrant"
+	_ "cosmossdk.io/x/feegrant/module"
+	_ "cosmossdk.io/x/upgrade"
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/testutil/configurator"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	_ "github.com/cosmos/cosmos-sdk/x/authz/module"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	_ "github.com/cosmos/cosmos-sdk/x/distribution"
+	_ "github.com/cosmos/cosmos-sdk/x/gov"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	_ "github.com/cosmos/cosmos-sdk/x/mint"
+	_ "github.com/cosmos/cosmos-sdk/x/slashing"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/account"
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+// fullAppConfig returns the config of an app with the modules queried by the helpers of the suite.
+func fullAppConfig() depinject.Config {
+	return configurator.NewAppConfig(
+		configurator.AuthModule(),
+		configurator.ParamsModule(),
+		configurator.BankModule(),
+		configurator.GenutilModule(),
+		configurator.StakingModule(),
+		configurator.DistributionModule(),
+		configurator.SlashingModule(),
+		configurator.MintModule(),
+		configurator.GovModule(),
+		configurator.FeegrantModule(),
+		configurator.AuthzModule(),
+		upgradeModule(),
+		configurator.ConsensusModule(),
+		configurator.TxModule(),
+	)
+}
+
+// upgradeModule adds the upgrade module, which the configurator has no option for.
+func upgradeModule() configurator.ModuleOption {
+	return func(config *configurator.Config) {
+		config.ModuleConfigs[upgradetypes.ModuleName] = &appv1alpha1.ModuleConfig{
+			Name:   upgradetypes.ModuleName,
+			Config: appconfig.WrapAny(&upgrademodulev1.Module{}),
+		}
+	}
+}
+
+func TestQueries(t *testing.T) {
+	ctx := context.Background()
+	// the list helpers are queried one item per page, so that every list spans several pages
+	onePerPage := network.WithPageSize(1)
+
+	metadata := []banktypes.Metadata{denomMetadata("atom"), denomMetadata("btc"), denomMetadata("eth")}
+	coins := sdk.NewCoins(
+		sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000_000),
+		sdk.NewInt64Coin("uatom", 100),
+		sdk.NewInt64Coin("ubtc", 200),
+		sdk.NewInt64Coin("ueth", 300),
+	)
+
+	grantee := account.NewAccount()
+	granters := []*account.Account{account.NewAccount(), account.NewAccount(), account.NewAccount()}
+	msgTypeURLs := []string{
+		sdk.MsgTypeURL(&banktypes.MsgSend{}),
+		sdk.MsgTypeURL(&govv1.MsgVote{}),
+		sdk.MsgTypeURL(&stakingtypes.MsgDelegate{}),
+	}
+
+	cfg := network.NewConfig(fullAppConfig(),
+		network.GovVotingPeriod(time.Minute),
+		network.SetDenomMetadata(metadata...),
+		network.ModuleGenesis[feegrant.GenesisState](feegrant.ModuleName, func(gs *feegrant.GenesisState) {
+			for _, granter := range granters {
+				grant, err := feegrant.NewGrant(granter.Address(), grantee.Address(), &feegrant.BasicAllowance{})
+				require.NoError(t, err)
+				gs.Allowances = append(gs.Allowances, grant)
+			}
+		}),
+		network.ModuleGenesis[authz.GenesisState](authz.ModuleName, func(gs *authz.GenesisState) {
+			for _, msgTypeURL := range msgTypeURLs {
+				authorization, err := codectypes.NewAnyWithValue(authz.NewGenericAuthorization(msgTypeURL))
+				require.NoError(t, err)
+				gs.Authorization = append(gs.Authorization, authz.GrantAuthorization{
+					Granter:       granters[0].Address().String(),
+					Grantee:       grantee.Address().String(),
+					Authorization: authorization,
+				})
+			}
+		}),
+	)
+	cfg.NumValidators = 3
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(2, coins))
+
+	_, err := s.Network.WaitForHeightWithTimeout(3, time.Minute)
+	require.NoError(t, err)
+
+	t.Run("gov", func(t *testing.T) {
+		params, err := s.GovParams()
+		require.NoError(t, err)
+		require.Equal(t, time.Minute, *params.VotingPeriod)
+
+		// submit several proposals in a single tx
+		proposer := s.Accounts[0]
+		var msgs []sdk.Msg
+		for _, title := range []string{"first", "second", "third"} {
+			msg, err := govv1.NewMsgSubmitProposal(nil, params.MinDeposit, proposer.Address().String(), title, title, title, false)
+			require.NoError(t, err)
+			msgs = append(msgs, msg)
+		}
+		bz, err := s.CreateTxBytes(ctx, network.TxGenInfo{
+			Account:  proposer,
+			GasLimit: 1_000_000,
+			Fee:      sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10)),
+		}, msgs...)
+		require.NoError(t, err)
+		requireTxSuccess(t, s, bz)
+
+		proposals, err := s.Proposals(onePerPage)
+		require.NoError(t, err)
+		require.Len(t, proposals, len(msgs))
+		for i, proposal := range proposals {
+			require.Equal(t, msgs[i].(*govv1.MsgSubmitProposal).Title, proposal.Title)
+			require.Equal(t, govv1.StatusVotingPeriod, proposal.Status)
+		}
+
+		proposal, err := s.Proposal(proposals[1].Id)
+		require.NoError(t, err)
+		require.Equal(t, "second", proposal.Title)
+	})
+
+	t.Run("feegrant", func(t *testing.T) {
+		allowances, err := s.FeeAllowances(grantee.Address().String(), onePerPage)
+		require.NoError(t, err)
+		require.Len(t, allowances, len(granters))
+
+		for _, granter := range granters {
+			allowance, err := s.FeeAllowance(granter.Address().String(), grantee.Address().String())
+			require.NoError(t, err)
+			require.Equal(t, granter.Address().String(), allowance.Granter)
+			require.Equal(t, grantee.Address().String(), allowance.Grantee)
+			grant, err := allowance.GetGrant()
+			require.NoError(t, err)
+			require.IsType(t, &feegrant.BasicAllowance{}, grant)
+		}
+	})
+
+	t.Run("authz", func(t *testing.T) {
+		granter := granters[0].Address().String()
+
+		grants, err := s.AuthzGrants(granter, grantee.Address().String(), "", onePerPage)
+		require.NoError(t, err)
+		require.Len(t, grants, len(msgTypeURLs))
+
+		grants, err = s.AuthzGrants(granter, grantee.Address().String(), msgTypeURLs[1])
+		require.NoError(t, err)
+		require.Len(t, grants, 1)
+		authorization, err := grants[0].GetAuthorization()
+		require.NoError(t, err)
+		require.Equal(t, msgTypeURLs[1], authorization.MsgTypeURL())
+	})
+
+	t.Run("slashing", func(t *testing.T) {
+		infos, err := s.SigningInfos(onePerPage)
+		require.NoError(t, err)
+		require.Len(t, infos, cfg.NumValidators)
+
+		info, err := s.SigningInfo(infos[1].Address)
+		require.NoError(t, err)
+		require.Equal(t, infos[1].Address, info.Address)
+	})
+
+	t.Run("mint", func(t *testing.T) {
+		inflation, err := s.Inflation()
+		require.NoError(t, err)
+		require.True(t, inflation.IsPositive(), "inflation %s", inflation)
+	})
+
+	t.Run("upgrade", func(t *testing.T) {
+		plan, err := s.UpgradePlan()
+		require.NoError(t, err)
+		require.Nil(t, plan)
+	})
+
+	t.Run("bank", func(t *testing.T) {
+		// pin the height, as the supply of the bond denom grows with inflation
+		latest, err := s.Network.LatestHeight()
+		require.NoError(t, err)
+		state := s.StateAt(latest)
+
+		supply, err := state.TotalSupply(onePerPage)
+		require.NoError(t, err)
+		all, err := state.TotalSupply()
+		require.NoError(t, err)
+		require.Equal(t, all, supply)
+		for _, coin := range coins {
+			require.True(t, supply.AmountOf(coin.Denom).GTE(coin.Amount.MulRaw(2)), "supply of %s", coin.Denom)
+		}
+
+		btc, err := state.SupplyOf("ubtc")
+		require.NoError(t, err)
+		require.Equal(t, sdk.NewInt64Coin("ubtc", 2*200), btc)
+		require.Equal(t, btc, sdk.NewCoin("ubtc", supply.AmountOf("ubtc")))
+
+		metadatas, err := s.DenomsMetadata(onePerPage)
+		require.NoError(t, err)
+		require.Equal(t, metadata, metadatas)
+
+		md, err := s.DenomMetadata("ueth")
+		require.NoError(t, err)
+		require.Equal(t, metadata[2], md)
+	})
+
+	t.Run("distribution", func(t *testing.T) {
+		// validators self-delegate at genesis, and their delegations earn the inflation
+		val := s.Network.Validators[0]
+		rewards, err := s.DelegatorRewards(val.Address.String())
+		require.NoError(t, err)
+		require.Len(t, rewards.Rewards, 1)
+		require.Equal(t, val.ValAddress.String(), rewards.Rewards[0].ValidatorAddress)
+		require.False(t, rewards.Total.IsZero())
+	})
+}
+
+// denomMetadata returns the metadata of a token whose base denom is the display denom prefixed with u.
+func denomMetadata(display string) banktypes.Metadata {
+	base := "u" + display
+	return banktypes.Metadata{
+		Description: display + " token",
+		DenomUnits: []*banktypes.DenomUnit{
+			{Denom: base, Exponent: 0},
+			{Denom: display, Exponent: 6},
+		},
+		Base:    base,
+		Display: display,
+		Name:    display,
+		Symbol:  display,
+	}
+}
@@ -0,0 +1,90 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	cmtcfg "github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	"github.com/cometbft/cometbft/p2p"
+	"github.com/cometbft/cometbft/privval"
+	"github.com/cosmos/go-bip39"
+
+	"github.com/skip-mev/chaintestutil/account"
+	"github.com/skip-mev/chaintestutil/sample"
+)
+
+// WithSeed returns a ConfigOption deriving the chain ID of the config from the seed. The mnemonics of the
+// validators not set in Mnemonics, their consensus and node keys, the accounts funded by NewSuite and
+// TestSuite.Rand are derived from the seed as well, so that a network can be reproduced from its seed. The seed
+// of a config built by NewConfig can also be set with the CHAINTESTUTIL_SEED environment variable.
+func WithSeed(seed int64) ConfigOption {
+	return func(cfg *Config) error {
+		if seed == 0 {
+			return errors.New("the seed must not be zero, which leaves the network unseeded")
+		}
+
+		cfg.Seed = seed
+
+		r := seededRand(seed, "chain-id")
+		cfg.ChainID = fmt.Sprintf("chain-%s", sample.AlphaString(r, 6))
+
+		return nil
+	}
+}
+
+// seededRand returns a source of randomness derived from the seed for the given purpose, so that the values
+// derived for a purpose do not depend on how many values are derived for the others.
+func seededRand(seed int64, purpose string) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(purpose))
+
+	return sample.RandWithSeed(seed ^ int64(h.Sum64()))
+}
+
+// randomBytes returns n bytes read from r.
+func randomBytes(r *rand.Rand, n int) []byte {
+	bz := make([]byte, n)
+	_, _ = r.Read(bz)
+
+	return bz
+}
+
+// seededMnemonic returns the mnemonic of the i-th validator derived from the seed.
+func seededMnemonic(seed int64, i int) (string, error) {
+	r := seededRand(seed, fmt.Sprintf("mnemonic-%d", i))
+
+	return bip39.NewMnemonic(randomBytes(r, 32))
+}
+
+// writeSeededNodeKeys writes the consensus and node keys of the i-th validator derived from the seed to its
+// config dir, so that they are loaded instead of being generated when initializing the node.
+func writeSeededNodeKeys(cmtCfg *cmtcfg.Config, seed int64, i int) error {
+	r := seededRand(seed, fmt.Sprintf("node-keys-%d", i))
+
+	if err := os.MkdirAll(filepath.Dir(cmtCfg.PrivValidatorStateFile()), 0o755); err != nil {
+		return err
+	}
+
+	privval.NewFilePV(ed25519.GenPrivKeyFromSecret(randomBytes(r, 32)),
+		cmtCfg.PrivValidatorKeyFile(), cmtCfg.PrivValidatorStateFile()).Save()
+
+	nodeKey := p2p.NodeKey{PrivKey: ed25519.GenPrivKeyFromSecret(randomBytes(r, 32))}
+	return nodeKey.SaveAs(cmtCfg.NodeKeyFile())
+}
+
+// seededAccounts returns n accounts derived from the seed.
+func seededAccounts(seed int64, n int) []account.Account {
+	r := seededRand(seed, "accounts")
+
+	accounts := make([]account.Account, n)
+	for i := range accounts {
+		accounts[i] = *account.NewAccountFromSecret(randomBytes(r, 32))
+	}
+
+	return accounts
+}
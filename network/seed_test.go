@@ -0,0 +1,45 @@
+package network_test
+
+import (
+	"testing"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/account"
+	"github.com/skip-mev/chaintestutil/network"
+	"github.com/skip-mev/chaintestutil/sample"
+)
+
+func TestSeed(t *testing.T) {
+	t.Run("same seed gives same config", func(t *testing.T) {
+		cfg := network.NewConfig(sdknetwork.MinimumAppConfig(), network.WithSeed(42))
+		other := network.NewConfig(sdknetwork.MinimumAppConfig(), network.WithSeed(42))
+		require.Equal(t, int64(42), cfg.Seed)
+		require.Equal(t, cfg.ChainID, other.ChainID)
+
+		other = network.NewConfig(sdknetwork.MinimumAppConfig(), network.WithSeed(43))
+		require.NotEqual(t, cfg.ChainID, other.ChainID)
+	})
+
+	t.Run("random seed by default", func(t *testing.T) {
+		cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+		other := network.NewConfig(sdknetwork.MinimumAppConfig())
+		require.NotZero(t, cfg.Seed)
+		require.NotEqual(t, cfg.Seed, other.Seed)
+
+		require.Error(t, network.Configure(&cfg, network.WithSeed(0)))
+	})
+
+	t.Run("seed from environment", func(t *testing.T) {
+		t.Setenv(sample.SeedEnv, "42")
+
+		cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+		require.Equal(t, int64(42), cfg.Seed)
+	})
+
+	t.Run("successive rands differ", func(t *testing.T) {
+		require.NotEqual(t, sample.Rand().Int63(), sample.Rand().Int63())
+		require.NotEqual(t, account.NewAccount().Address(), account.NewAccount().Address())
+	})
+}
@@ -0,0 +1,88 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	storetypes "cosmossdk.io/store/types"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// WithSnapshots returns a ConfigOption enabling the state sync snapshots of the nodes, taken every interval
+// blocks. The keepRecent most recent snapshots are kept, or all of them if zero. Snapshots are taken by the apps
+// built by NewAppConstructor; custom app constructors should read the state sync settings from the app config
+// of the validator.
+func WithSnapshots(interval uint64, keepRecent uint32) ConfigOption {
+	return func(cfg *Config) error {
+		if interval == 0 {
+			return errors.New("snapshot interval must be positive")
+		}
+
+		cfg.SnapshotInterval = interval
+		cfg.SnapshotKeepRecent = keepRecent
+
+		return nil
+	}
+}
+
+// RequireStateSyncNode adds a full node that state syncs from the snapshots of the validators, see
+// WithStateSync, and fails the test if it does not catch up with the network within the timeout. Once caught up,
+// the app hash of the node must match the app hash of the validators at its latest height. The node is returned.
+func (s *TestSuite) RequireStateSyncNode(t testing.TB, timeout time.Duration) *Validator {
+	t.Helper()
+
+	node, err := s.Network.AddFullNode(WithStateSync())
+	require.NoError(t, err)
+
+	var status *coretypes.ResultStatus
+	require.Eventually(t, func() bool {
+		status, err = node.RPCClient.Status(context.Background())
+		return err == nil && !status.SyncInfo.CatchingUp && status.SyncInfo.LatestBlockHeight > 0
+	}, timeout, time.Second, "full node did not catch up with the network")
+
+	// a node replaying the chain keeps the blocks from genesis
+	require.Greater(t, status.SyncInfo.EarliestBlockHeight, int64(1), "full node synced from genesis instead of a snapshot")
+
+	height := status.SyncInfo.LatestBlockHeight
+	nodeInfo, err := commitInfo(node, height)
+	require.NoError(t, err)
+
+	_, err = s.Network.WaitForHeight(height)
+	require.NoError(t, err)
+
+	for i, val := range s.Network.Validators {
+		if !val.IsRunning() {
+			continue
+		}
+
+		valInfo, err := commitInfo(val, height)
+		require.NoError(t, err)
+		require.Equal(t, valInfo.Hash(), nodeInfo.Hash(), "app hash of the full node differs from validator %d at height %d", i, height)
+	}
+
+	return node
+}
+
+// commitInfo returns the commit info of the multistore of the node's app at the given height, which holds the
+// app hash and the hash of every store.
+func commitInfo(v *Validator, height int64) (*storetypes.CommitInfo, error) {
+	app, ok := v.app.(interface {
+		CommitMultiStore() storetypes.CommitMultiStore
+	})
+	if !ok {
+		return nil, fmt.Errorf("the app of %s does not expose its multistore", v.Moniker)
+	}
+
+	cms, ok := app.CommitMultiStore().(interface {
+		GetCommitInfo(version int64) (*storetypes.CommitInfo, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("the multistore of %s does not expose its commit info", v.Moniker)
+	}
+
+	return cms.GetCommitInfo(height)
+}
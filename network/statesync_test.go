@@ -0,0 +1,42 @@
+package network_test
+
+import (
+	"testing"
+	"time"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestRequireStateSyncNode(t *testing.T) {
+	t.Run("snapshots disabled", func(t *testing.T) {
+		cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+		require.Error(t, network.Configure(&cfg, network.WithSnapshots(0, 0)))
+
+		s := network.NewSuite(t, cfg)
+		_, err := s.Network.AddFullNode(network.WithStateSync())
+		require.ErrorContains(t, err, "WithSnapshots")
+	})
+
+	t.Run("snapshots enabled", func(t *testing.T) {
+		coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000))
+		cfg := network.NewConfig(sdknetwork.MinimumAppConfig(), network.WithSnapshots(2, 0))
+		cfg.NumValidators = 2
+		s := network.NewSuite(t, cfg, network.WithFundedAccounts(1, coins))
+
+		_, err := s.Network.WaitForHeightWithTimeout(4, time.Minute)
+		require.NoError(t, err)
+
+		node := s.RequireStateSyncNode(t, time.Minute)
+		require.Len(t, s.Network.FullNodes, 1)
+		require.Same(t, node, s.Network.FullNodes[0])
+
+		// the full node serves the state restored from the snapshot
+		balances, err := s.FullNode(0).Balances(s.Accounts[0])
+		require.NoError(t, err)
+		require.Equal(t, coins, balances)
+	})
+}
@@ -3,93 +3,331 @@ package network
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
 	"testing"
 
 	cmthttp "github.com/cometbft/cometbft/rpc/client/http"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	"github.com/cosmos/cosmos-sdk/client"
 	clienttx "github.com/cosmos/cosmos-sdk/client/tx"
-	"github.com/cosmos/cosmos-sdk/codec"
-	"github.com/cosmos/cosmos-sdk/testutil/network"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	txmodule "github.com/cosmos/cosmos-sdk/x/auth/tx/config"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
-	distrtypes "github.com/cosmos/cosmos-sdk/x/distribution/types"
-	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/skip-mev/chaintestutil/account"
-	"github.com/skip-mev/chaintestutil/encoding"
+	"github.com/skip-mev/chaintestutil/sample"
 )
 
-var cdc *codec.ProtoCodec
-
-func init() {
-	cfg := encoding.MakeTestEncodingConfig()
-	banktypes.RegisterInterfaces(cfg.InterfaceRegistry)
-	stakingtypes.RegisterInterfaces(cfg.InterfaceRegistry)
-	distrtypes.RegisterInterfaces(cfg.InterfaceRegistry)
-
-	cdc = codec.NewProtoCodec(cfg.InterfaceRegistry)
-}
+// clientCacheMtx guards the creation of the client cache of the suites not created through NewSuite, whose
+// helpers may be called concurrently.
+var clientCacheMtx sync.Mutex
 
 // TestSuite is a test suite for tests that initializes a network instance.
 type TestSuite struct {
 	Network *Network
+
+	// Accounts are the accounts funded in genesis through WithFundedAccounts.
+	Accounts []account.Account
+
+	// node is the index of the node targeted by the suite's helpers, among the validators followed by the full
+	// nodes.
+	node int
+	// height is the block height queried by the suite's query helpers, the latest height is queried if zero.
+	height int64
+	// clients caches the clients of every node, shared by all views of the suite.
+	clients *clientCache
+	// rand is the source of randomness of the suite, derived from the seed of the network if it is seeded.
+	rand *rand.Rand
 }
 
-func NewSuite(t *testing.T, cfg network.Config) *TestSuite {
-	return &TestSuite{Network: New(t, cfg)}
+// SuiteOption represents an option that can be provided to NewSuite.
+type SuiteOption func(*SuiteOptions)
+
+// SuiteOptions represents the options to configure the network of a TestSuite.
+type SuiteOptions struct {
+	// NumFundedAccounts is the number of accounts to generate and fund in genesis.
+	NumFundedAccounts int
+	// FundedAccountBalance is the balance each generated account is funded with.
+	FundedAccountBalance sdk.Coins
 }
 
-// GetGRPC returns a grpc client for the first validator's node.
-func (s *TestSuite) GetGRPC() (cc *grpc.ClientConn, close func(), err error) {
-	// get grpc address
-	grpcAddr := s.Network.Validators[0].AppConfig.GRPC.Address
+// WithFundedAccounts generates n accounts which are added to the auth and bank genesis state with
+// the given balance, so that they can transact from the first block. The accounts are exposed through
+// TestSuite.Accounts.
+func WithFundedAccounts(n int, balance sdk.Coins) SuiteOption {
+	return func(options *SuiteOptions) {
+		options.NumFundedAccounts = n
+		options.FundedAccountBalance = balance
+	}
+}
+
+// NewSuite creates a TestSuite with a running network for the given config.
+func NewSuite(t *testing.T, cfg Config, options ...SuiteOption) *TestSuite {
+	// run all options before setup
+	var so SuiteOptions
+	for _, option := range options {
+		option(&so)
+	}
+
+	// copy the genesis state so that the caller's config is left untouched
+	cfg.GenesisState = maps.Clone(cfg.GenesisState)
+
+	var (
+		accounts []account.Account
+		r        *rand.Rand
+	)
+	if cfg.Seed != 0 {
+		accounts = seededAccounts(cfg.Seed, so.NumFundedAccounts)
+		r = seededRand(cfg.Seed, "suite")
+	} else {
+		accounts = make([]account.Account, so.NumFundedAccounts)
+		for i := range accounts {
+			accounts[i] = *account.NewAccount()
+		}
+		r = sample.Rand()
+	}
+
+	genAccounts := make([]authtypes.GenesisAccount, so.NumFundedAccounts)
+	balances := make([]banktypes.Balance, so.NumFundedAccounts)
+	for i := range accounts {
+		addr, err := cfg.TxConfig.SigningContext().AddressCodec().BytesToString(accounts[i].Address())
+		require.NoError(t, err)
+
+		genAccounts[i] = authtypes.NewBaseAccount(accounts[i].Address(), nil, 0, 0)
+		balances[i] = banktypes.Balance{
+			Address: addr,
+			Coins:   so.FundedAccountBalance,
+		}
+	}
+
+	require.NoError(t, ModifyGenesis(&cfg, AddAccounts(genAccounts...), AddBalances(balances...)))
+
+	s := &TestSuite{
+		Network:  New(t, cfg),
+		Accounts: accounts,
+		clients:  newClientCache(),
+		rand:     r,
+	}
+
+	// cleanups run in reverse order, so the clients are closed before the network is stopped
+	t.Cleanup(s.clients.close)
+
+	return s
+}
+
+// Rand returns the source of randomness of the suite, e.g. for the sample generators. It is derived from the
+// seed of the network, so that the generated data is reproduced along with the network. It is shared by all
+// views of the suite and is not safe for concurrent use.
+func (s *TestSuite) Rand() *rand.Rand {
+	return s.rand
+}
+
+// Node returns a view of the suite whose queries, txs and clients target the node of the i-th validator
+// instead of the first one.
+func (s *TestSuite) Node(i int) *TestSuite {
+	if i < 0 || i >= len(s.Network.Validators) {
+		panic(fmt.Sprintf("validator index %d out of range [0, %d)", i, len(s.Network.Validators)))
+	}
+
+	node := s.view()
+	node.node = i
+	return node
+}
+
+// Nodes returns a view of the suite for each validator of the network, see Node.
+func (s *TestSuite) Nodes() []*TestSuite {
+	nodes := make([]*TestSuite, len(s.Network.Validators))
+	for i := range nodes {
+		nodes[i] = s.Node(i)
+	}
+
+	return nodes
+}
+
+// FullNode returns a view of the suite whose queries, txs and clients target the i-th full node of the network,
+// see WithFullNodes and Network.AddFullNode. Txs are broadcast through the full node, but the helpers signing
+// with the keys of the targeted validator are not available, as full nodes have no keys.
+func (s *TestSuite) FullNode(i int) *TestSuite {
+	if i < 0 || i >= len(s.Network.FullNodes) {
+		panic(fmt.Sprintf("full node index %d out of range [0, %d)", i, len(s.Network.FullNodes)))
+	}
+
+	node := s.view()
+	node.node = len(s.Network.Validators) + i
+	return node
+}
+
+// FullNodes returns a view of the suite for each full node of the network, see FullNode.
+func (s *TestSuite) FullNodes() []*TestSuite {
+	nodes := make([]*TestSuite, len(s.Network.FullNodes))
+	for i := range nodes {
+		nodes[i] = s.FullNode(i)
+	}
+
+	return nodes
+}
+
+// fullNodeIndex returns the index of the targeted node among the full nodes, if it is a full node.
+func (s *TestSuite) fullNodeIndex() (int, bool) {
+	i := s.node - len(s.Network.Validators)
+	return i, i >= 0
+}
+
+// StateAt returns a view of the suite whose query helpers return the state at the given height, instead of
+// the state at the latest height. A height of zero queries the latest state.
+func (s *TestSuite) StateAt(height int64) *TestSuite {
+	if height < 0 {
+		panic(fmt.Sprintf("invalid height %d", height))
+	}
+
+	view := s.view()
+	view.height = height
+	return view
+}
+
+// view returns a copy of the suite sharing its clients.
+func (s *TestSuite) view() *TestSuite {
+	// create the client cache before the copy so that it is shared
+	s.clientCache()
+
+	view := *s
+	return &view
+}
+
+// queryContext returns the context used by the query helpers, which sets the block height of the queried
+// state if the suite is a view of the state at a given height.
+func (s *TestSuite) queryContext() context.Context {
+	ctx := context.Background()
+	if s.height == 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(s.height, 10))
+}
+
+// Validator returns the validator whose node is targeted by the suite, or the targeted full node.
+func (s *TestSuite) Validator() *Validator {
+	if i, ok := s.fullNodeIndex(); ok {
+		return s.Network.FullNodes[i]
+	}
 
-	// create the client
-	cc, err = grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	return s.Network.Validators[s.node]
+}
+
+// RequireConsistentQuery runs the query against the node of every validator and requires all responses
+// to be equal, returning the response of the first validator. Note that nodes may be at different
+// heights when queried, so the query should either depend on settled state only, or target a given
+// height through StateAt.
+func RequireConsistentQuery[T any](t testing.TB, s *TestSuite, query func(node *TestSuite) (T, error)) T {
+	t.Helper()
+
+	var expected T
+	for i, node := range s.Nodes() {
+		resp, err := query(node)
+		require.NoError(t, err, "query failed on validator %d", i)
+
+		if i == 0 {
+			expected = resp
+			continue
+		}
+
+		require.Equal(t, expected, resp, "response of validator %d differs from validator 0", i)
+	}
+
+	return expected
+}
+
+// GRPC returns the gRPC connection to the targeted validator's node. The connection is shared by the
+// suite and closed when the test finishes, so it must not be closed by the caller.
+func (s *TestSuite) GRPC() (*grpc.ClientConn, error) {
+	return s.clientCache().grpcConn(s.Validator().AppConfig.GRPC.Address, s.Network.Config.InterfaceRegistry)
+}
+
+// GetGRPC returns a grpc client for the targeted validator's node.
+//
+// Deprecated: use GRPC, which returns a connection shared by the suite. The returned close function
+// is a no-op.
+func (s *TestSuite) GetGRPC() (cc *grpc.ClientConn, close func(), err error) {
+	cc, err = s.GRPC()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	close = func() { cc.Close() }
+	return cc, func() {}, nil
+}
+
+// clientCache returns the client cache of the suite. Suites that were not created through NewSuite
+// get a cache whose clients are not closed on cleanup.
+func (s *TestSuite) clientCache() *clientCache {
+	clientCacheMtx.Lock()
+	defer clientCacheMtx.Unlock()
 
-	return
+	if s.clients == nil {
+		s.clients = newClientCache()
+	}
+
+	return s.clients
 }
 
-// CreateValidatorTxBytes creates tx bytes using the first validators keyring.
+// CreateValidatorTxBytes creates tx bytes signed by the targeted validator's account with SIGN_MODE_DIRECT, at the
+// current sequence of the account. See CreateTxBytes to sign with other modes.
 func (s *TestSuite) CreateValidatorTxBytes(fees sdk.Coin, gas uint64, msgs []sdk.Msg) ([]byte, error) {
-	val := s.Network.Validators[0]
-
-	kr, err := val.ClientCtx.Keyring.KeyByAddress(val.Address)
+	acc, err := s.ValidatorAccount()
 	if err != nil {
 		return nil, err
 	}
 
-	txFactory := clienttx.Factory{}.
-		WithChainID(s.Network.Config.ChainID).
-		WithKeybase(val.ClientCtx.Keyring).
-		WithTxConfig(val.ClientCtx.TxConfig).
-		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).WithFees(fees.String()).
-		WithGas(gas).
-		WithSequence(1)
-	builder, err := txFactory.BuildUnsignedTx(msgs...)
-	if err != nil {
-		return nil, err
+	return s.CreateTxBytes(context.Background(), TxGenInfo{
+		Account:  acc,
+		GasLimit: gas,
+		Fee:      sdk.NewCoins(fees),
+	}, msgs...)
+}
+
+// ValidatorAccount returns the account of the targeted validator, loaded from its keyring, so that txs can be
+// created on behalf of the validator with CreateTxBytes.
+func (s *TestSuite) ValidatorAccount() (account.Account, error) {
+	val := s.Validator()
+	if val.ClientCtx.Keyring == nil {
+		return account.Account{}, fmt.Errorf("node %d is a full node without keys", s.node)
 	}
-	err = clienttx.Sign(context.Background(), txFactory, kr.Name, builder, true)
+
+	record, err := val.ClientCtx.Keyring.KeyByAddress(val.Address)
 	if err != nil {
-		return nil, err
+		return account.Account{}, err
+	}
+
+	local := record.GetLocal()
+	if local == nil {
+		return account.Account{}, fmt.Errorf("key %s of validator %d is not stored locally", record.Name, s.node)
+	}
+
+	pk, ok := local.PrivKey.GetCachedValue().(cryptotypes.PrivKey)
+	if !ok {
+		return account.Account{}, fmt.Errorf("failed to load the private key of validator %d", s.node)
 	}
 
-	bz, err := val.ClientCtx.TxConfig.TxEncoder()(builder.GetTx())
-	return bz, err
+	return *account.NewAccountFromPrivKey(pk), nil
 }
 
+// GetCometClient returns a CometBFT RPC client for the targeted validator's node. The client is shared
+// by the suite and stopped when the test finishes.
 func (s *TestSuite) GetCometClient() (*cmthttp.HTTP, error) {
-	return cmthttp.New(s.Network.Validators[0].RPCAddress, "/websocket")
+	return s.clientCache().cometClient(s.Validator().RPCAddress)
 }
 
 // TxGenInfo contains common info for generating transactions for tests.
@@ -102,25 +340,85 @@ type TxGenInfo struct {
 	OverrideSequence bool
 	// Sequence is the account sequence to be used if OverrideSequence is true.
 	Sequence uint64
+	// OverrideAccountNumber will manually set the account number for signing using AccountNumber. The account is
+	// not queried if both its sequence and its account number are overridden.
+	OverrideAccountNumber bool
+	// AccountNumber is the account number to be used if OverrideAccountNumber is true.
+	AccountNumber uint64
+	// SignMode is the sign mode used by Account to sign the transaction. SIGN_MODE_DIRECT is used if unspecified.
+	SignMode signing.SignMode
+	// FeePayer is an optional account paying the fees of the transaction. It is required for SIGN_MODE_DIRECT_AUX,
+	// as the fee payer is not allowed to sign in that mode. The fee payer always signs using SIGN_MODE_DIRECT.
+	FeePayer *account.Account
+}
+
+// txSigner contains the info needed to sign a transaction on behalf of a single account.
+type txSigner struct {
+	account       account.Account
+	signMode      signing.SignMode
+	accountNumber uint64
+	sequence      uint64
 }
 
 // CreateTxBytes creates and signs a transaction, from the given messages.
 func (s *TestSuite) CreateTxBytes(ctx context.Context, txGen TxGenInfo, msgs ...sdk.Msg) ([]byte, error) {
-	accI, err := s.AccountI(txGen.Account)
-	if err != nil {
-		return nil, err
+	signMode := txGen.SignMode
+	if signMode == signing.SignMode_SIGN_MODE_UNSPECIFIED {
+		signMode = signing.SignMode_SIGN_MODE_DIRECT
 	}
 
-	txConfig := s.Network.Validators[0].ClientCtx.TxConfig
-	sequence := accI.GetSequence()
-	if txGen.OverrideSequence {
-		sequence = txGen.Sequence
+	if signMode == signing.SignMode_SIGN_MODE_DIRECT_AUX && txGen.FeePayer == nil {
+		return nil, fmt.Errorf("a fee payer is required when signing with %s", signMode)
+	}
+
+	// always sign using the latest account state, even from a view of the state at a past height
+	latest := s.StateAt(0)
+
+	accountNumber, sequence := txGen.AccountNumber, txGen.Sequence
+	if !txGen.OverrideAccountNumber || !txGen.OverrideSequence {
+		accI, err := latest.AccountI(txGen.Account)
+		if err != nil {
+			return nil, err
+		}
+
+		if !txGen.OverrideAccountNumber {
+			accountNumber = accI.GetAccountNumber()
+		}
+		if !txGen.OverrideSequence {
+			sequence = accI.GetSequence()
+		}
+	}
+
+	signers := []txSigner{{
+		account:       txGen.Account,
+		signMode:      signMode,
+		accountNumber: accountNumber,
+		sequence:      sequence,
+	}}
+
+	if txGen.FeePayer != nil {
+		feePayerI, err := latest.AccountI(*txGen.FeePayer)
+		if err != nil {
+			return nil, err
+		}
+
+		signers = append(signers, txSigner{
+			account:       *txGen.FeePayer,
+			signMode:      signing.SignMode_SIGN_MODE_DIRECT,
+			accountNumber: feePayerI.GetAccountNumber(),
+			sequence:      feePayerI.GetSequence(),
+		})
+	}
+
+	txConfig, err := s.signingTxConfig(signMode)
+	if err != nil {
+		return nil, err
 	}
 
 	txFactory := clienttx.Factory{}.
 		WithChainID(s.Network.Config.ChainID).
 		WithTxConfig(txConfig).
-		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT).
+		WithSignMode(signMode).
 		WithSequence(sequence)
 	builder, err := txFactory.BuildUnsignedTx(msgs...)
 	if err != nil {
@@ -135,42 +433,71 @@ func (s *TestSuite) CreateTxBytes(ctx context.Context, txGen TxGenInfo, msgs ...
 	builder.SetGasLimit(txGen.GasLimit)
 	builder.SetFeeAmount(txGen.Fee)
 	builder.SetTimeoutHeight(txGen.TimeoutHeight)
+	if txGen.FeePayer != nil {
+		builder.SetFeePayer(txGen.FeePayer.Address())
+	}
 
-	sigV2 := signing.SignatureV2{
-		PubKey: txGen.Account.PubKey(),
-		Data: &signing.SingleSignatureData{
-			SignMode:  txFactory.SignMode(),
-			Signature: nil,
-		},
-		Sequence: sequence,
+	// set empty signatures first, so that the signer infos are populated for all sign modes
+	sigs := make([]signing.SignatureV2, len(signers))
+	for i, signer := range signers {
+		sigs[i] = signing.SignatureV2{
+			PubKey: signer.account.PubKey(),
+			Data: &signing.SingleSignatureData{
+				SignMode:  signer.signMode,
+				Signature: nil,
+			},
+			Sequence: signer.sequence,
+		}
 	}
 
-	if err := builder.SetSignatures(sigV2); err != nil {
+	if err := builder.SetSignatures(sigs...); err != nil {
 		return nil, err
 	}
 
 	// now actually sign
-	signerData := authsigning.SignerData{
-		ChainID:       s.Network.Config.ChainID,
-		AccountNumber: accI.GetAccountNumber(),
-		Sequence:      sequence,
-		PubKey:        txGen.Account.PubKey(),
+	for i, signer := range signers {
+		signerData := authsigning.SignerData{
+			ChainID:       s.Network.Config.ChainID,
+			AccountNumber: signer.accountNumber,
+			Sequence:      signer.sequence,
+			PubKey:        signer.account.PubKey(),
+			Address:       signer.account.Address().String(),
+		}
+
+		sigs[i], err = clienttx.SignWithPrivKey(
+			ctx, signer.signMode, signerData,
+			builder, signer.account.PrivKey(), txConfig, signer.sequence,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	sigV2, err = clienttx.SignWithPrivKey(
-		ctx, signing.SignMode(txConfig.SignModeHandler().DefaultMode()), signerData,
-		builder, txGen.Account.PrivKey(), txConfig, sequence,
-	)
-	if err != nil {
+	if err := builder.SetSignatures(sigs...); err != nil {
 		return nil, err
 	}
 
-	if err := builder.SetSignatures(sigV2); err != nil {
+	// return tx
+	return txConfig.TxEncoder()(builder.GetTx())
+}
+
+// signingTxConfig returns a TxConfig able to produce sign bytes for the given sign mode. SIGN_MODE_TEXTUAL
+// requires coin metadata to render the sign bytes, which is queried from the targeted node over gRPC.
+func (s *TestSuite) signingTxConfig(signMode signing.SignMode) (client.TxConfig, error) {
+	txConfig := s.Validator().ClientCtx.TxConfig
+	if signMode != signing.SignMode_SIGN_MODE_TEXTUAL {
+		return txConfig, nil
+	}
+
+	cc, err := s.GRPC()
+	if err != nil {
 		return nil, err
 	}
 
-	// return tx
-	return txConfig.TxEncoder()(builder.GetTx())
+	return authtx.NewTxConfigWithOptions(s.Network.Config.Codec, authtx.ConfigOptions{
+		EnabledSignModes:           append(slices.Clone(authtx.DefaultSignModes), signing.SignMode_SIGN_MODE_TEXTUAL),
+		TextualCoinMetadataQueryFn: txmodule.NewGRPCCoinMetadataQueryFn(cc),
+	})
 }
 
 // BroadcastMode is a type alias for Tx broadcast modes.
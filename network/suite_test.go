@@ -0,0 +1,112 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestCreateTxBytes(t *testing.T) {
+	ctx := context.Background()
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000))
+	fee := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10))
+
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(2, coins))
+	sender, feePayer := s.Accounts[0], s.Accounts[1]
+	send := banktypes.NewMsgSend(sender.Address(), feePayer.Address(), sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1)))
+
+	for _, signMode := range []signing.SignMode{
+		signing.SignMode_SIGN_MODE_DIRECT,
+		signing.SignMode_SIGN_MODE_DIRECT_AUX,
+		signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+		signing.SignMode_SIGN_MODE_TEXTUAL,
+	} {
+		t.Run(signMode.String(), func(t *testing.T) {
+			txGen := network.TxGenInfo{
+				Account:  sender,
+				GasLimit: 200_000,
+				Fee:      fee,
+				SignMode: signMode,
+			}
+			if signMode == signing.SignMode_SIGN_MODE_DIRECT_AUX {
+				txGen.FeePayer = &feePayer
+			}
+
+			bz, err := s.CreateTxBytes(ctx, txGen, send)
+			require.NoError(t, err)
+			requireTxSuccess(t, s, bz)
+		})
+	}
+
+	t.Run("validator", func(t *testing.T) {
+		val := s.Network.Validators[0]
+		msg := banktypes.NewMsgSend(val.Address, sender.Address(), sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1)))
+
+		// the second tx is signed at the sequence following the first one
+		for i := 0; i < 2; i++ {
+			bz, err := s.CreateValidatorTxBytes(fee[0], 200_000, []sdk.Msg{msg})
+			require.NoError(t, err)
+			requireTxSuccess(t, s, bz)
+		}
+	})
+}
+
+// requireTxSuccess broadcasts the tx and requires it to be executed successfully.
+func requireTxSuccess(t *testing.T, s *network.TestSuite, bz []byte) {
+	t.Helper()
+
+	res, err := s.BroadcastTxCommit(context.Background(), bz)
+	require.NoError(t, err)
+
+	txRes, err := s.DecodeBroadcastTxCommit(res)
+	require.NoError(t, err)
+	require.NoError(t, txRes.Err())
+}
+
+func TestRequireConsistentQuery(t *testing.T) {
+	ctx := context.Background()
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000))
+	fee := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10))
+	amount := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 100))
+
+	cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+	cfg.NumValidators = 3
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(2, coins))
+	sender, recipient := s.Accounts[0], s.Accounts[1]
+
+	// send twice, so that the balance at the height of the first send differs from the latest one
+	var heights []int64
+	for i := 0; i < 2; i++ {
+		bz, err := s.CreateTxBytes(ctx, network.TxGenInfo{Account: sender, GasLimit: 200_000, Fee: fee},
+			banktypes.NewMsgSend(sender.Address(), recipient.Address(), amount))
+		require.NoError(t, err)
+		res, err := s.BroadcastTxCommit(ctx, bz)
+		require.NoError(t, err)
+		txRes, err := s.DecodeBroadcastTxCommit(res)
+		require.NoError(t, err)
+		require.NoError(t, txRes.Err())
+		heights = append(heights, txRes.Height)
+	}
+
+	// every node must have committed the pinned height to serve it
+	for _, node := range s.Nodes() {
+		require.Eventually(t, func() bool {
+			status, err := node.Validator().RPCClient.Status(ctx)
+			return err == nil && status.SyncInfo.LatestBlockHeight >= heights[0]
+		}, time.Minute, 100*time.Millisecond)
+	}
+
+	balances := network.RequireConsistentQuery(t, s.StateAt(heights[0]), func(node *network.TestSuite) (sdk.Coins, error) {
+		return node.Balances(recipient)
+	})
+	require.Equal(t, coins.Add(amount...), balances)
+}
@@ -0,0 +1,775 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"cosmossdk.io/core/address"
+	"cosmossdk.io/log"
+	sdkmath "cosmossdk.io/math"
+	"github.com/cometbft/cometbft/node"
+	cmtclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/client/grpc/cmtservice"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/server/api"
+	srvconfig "github.com/cosmos/cosmos-sdk/server/config"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	"github.com/cosmos/cosmos-sdk/testutil"
+	"github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+)
+
+// startupTimeout is how long a new network may take to commit its first block, which takes longer when several
+// networks start concurrently.
+const startupTimeout = 30 * time.Second
+
+var (
+	// running holds the networks of the test binary, cleaned up when the tests are interrupted by a signal.
+	running sync.Map
+	// trapOnce traps the signals once for all the networks of the test binary.
+	trapOnce sync.Once
+)
+
+type (
+	// Network defines a local in-process testing network. It is derived from the cosmos-sdk
+	// testutil/network package, but every validator exposes its own RPC, API and gRPC servers,
+	// so that tests can target any node of the network.
+	//
+	// Several networks may run concurrently, e.g. from parallel tests, as long as they share the bech32 prefixes
+	// of the process-wide SDK config, see checkSDKConfig. Any caller must be sure to Cleanup after testing is
+	// finished in order to release the ports and directories of the network.
+	Network struct {
+		Logger     Logger
+		BaseDir    string
+		Validators []*Validator
+		// FullNodes are the non-validator nodes of the network, see WithFullNodes and AddFullNode.
+		FullNodes []*Validator
+
+		Config Config
+
+		partition *partition
+	}
+
+	// Validator defines an in-process CometBFT validator node. Through this object,
+	// a client can make RPC and API calls and interact with any client command
+	// or handler.
+	Validator struct {
+		AppConfig   *srvconfig.Config
+		ClientCtx   client.Context
+		Ctx         *server.Context
+		Dir         string
+		NodeID      string
+		PubKey      cryptotypes.PubKey
+		Moniker     string
+		APIAddress  string
+		RPCAddress  string
+		P2PAddress  string
+		GRPCAddress string
+		Address     sdk.AccAddress
+		ValAddress  sdk.ValAddress
+		RPCClient   cmtclient.Client
+
+		app      servertypes.Application
+		tmNode   *node.Node
+		api      *api.Server
+		grpc     *grpc.Server
+		errGroup *errgroup.Group
+		cancelFn context.CancelFunc
+		dbs      map[string]*nodeDB
+		// dbsMu guards dbs, which are opened by the node while the suite reads them. It is a pointer as the
+		// validator is copied by its value receivers.
+		dbsMu *sync.Mutex
+		// partition is the partition of the network, applied by the peer filter of the node.
+		partition *partition
+		// ports reserves the ports of the node until it is started.
+		ports portReservation
+	}
+
+	// Logger is a network logger interface that exposes testnet-level Log() methods for an in-process testing network.
+	Logger = network.Logger
+)
+
+var _ ValidatorI = Validator{}
+
+func (v Validator) GetCtx() *server.Context {
+	return v.Ctx
+}
+
+func (v Validator) GetAppConfig() *srvconfig.Config {
+	return v.AppConfig
+}
+
+// newNetwork creates and starts a new Network in the given base directory.
+func newNetwork(l Logger, baseDir string, cfg Config) (*Network, error) {
+	if err := checkSDKConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	network := &Network{
+		Logger:     l,
+		BaseDir:    baseDir,
+		Validators: make([]*Validator, cfg.NumValidators),
+		Config:     cfg,
+		partition:  &partition{},
+	}
+
+	l.Logf("preparing test network with chain-id \"%s\"\n", cfg.ChainID)
+
+	monikers := make([]string, cfg.NumValidators)
+	nodeIDs := make([]string, cfg.NumValidators)
+	valPubKeys := make([]cryptotypes.PubKey, cfg.NumValidators)
+
+	var (
+		genAccounts []authtypes.GenesisAccount
+		genBalances []banktypes.Balance
+		genFiles    []string
+	)
+
+	buf := bufio.NewReader(os.Stdin)
+
+	// generate private keys, node IDs, and initial transactions
+	for i := 0; i < cfg.NumValidators; i++ {
+		v, err := newNode(cfg, network.BaseDir, i)
+		if err != nil {
+			return nil, err
+		}
+
+		cmtCfg := v.Ctx.Config
+		nodeDirName := v.Moniker
+		clientDir := filepath.Join(v.Dir, "simcli")
+		gentxsDir := filepath.Join(network.BaseDir, "gentxs")
+		monikers[i] = nodeDirName
+
+		if cfg.Seed != 0 {
+			if err := writeSeededNodeKeys(cmtCfg, cfg.Seed, i); err != nil {
+				return nil, err
+			}
+		}
+
+		nodeID, pubKey, err := genutil.InitializeNodeValidatorFiles(cmtCfg)
+		if err != nil {
+			return nil, err
+		}
+
+		nodeIDs[i] = nodeID
+		valPubKeys[i] = pubKey
+
+		kb, err := keyring.New(sdk.KeyringServiceName(), keyring.BackendTest, clientDir, buf, cfg.Codec, cfg.KeyringOptions...)
+		if err != nil {
+			return nil, err
+		}
+
+		keyringAlgos, _ := kb.SupportedAlgorithms()
+		algo, err := keyring.NewSigningAlgoFromString(cfg.SigningAlgo, keyringAlgos)
+		if err != nil {
+			return nil, err
+		}
+
+		var mnemonic string
+		if i < len(cfg.Mnemonics) {
+			mnemonic = cfg.Mnemonics[i]
+		} else if cfg.Seed != 0 {
+			if mnemonic, err = seededMnemonic(cfg.Seed, i); err != nil {
+				return nil, err
+			}
+		}
+
+		addr, secret, err := testutil.GenerateSaveCoinKey(kb, nodeDirName, mnemonic, true, algo)
+		if err != nil {
+			return nil, err
+		}
+
+		// if PrintMnemonic is set to true, we print the first validator node's secret to the network's logger
+		// for debugging and manual testing
+		if cfg.PrintMnemonic && i == 0 {
+			printMnemonic(l, secret)
+		}
+
+		info := map[string]string{"secret": secret}
+		infoBz, err := json.Marshal(info)
+		if err != nil {
+			return nil, err
+		}
+
+		// save private key seed words
+		err = writeFile(fmt.Sprintf("%v.json", "key_seed"), clientDir, infoBz)
+		if err != nil {
+			return nil, err
+		}
+
+		balances := sdk.NewCoins(
+			sdk.NewCoin(fmt.Sprintf("%stoken", nodeDirName), cfg.AccountTokens),
+			sdk.NewCoin(cfg.BondDenom, cfg.StakingTokens),
+		)
+
+		genFiles = append(genFiles, cmtCfg.GenesisFile())
+		addrStr, err := cfg.TxConfig.SigningContext().AddressCodec().BytesToString(addr)
+		if err != nil {
+			return nil, err
+		}
+		valAddrStr, err := cfg.TxConfig.SigningContext().ValidatorAddressCodec().BytesToString(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		genBalances = append(genBalances, banktypes.Balance{Address: addrStr, Coins: balances.Sort()})
+		genAccounts = append(genAccounts, authtypes.NewBaseAccount(addr, nil, 0, 0))
+
+		commission, err := sdkmath.LegacyNewDecFromStr("0.5")
+		if err != nil {
+			return nil, err
+		}
+
+		createValMsg, err := stakingtypes.NewMsgCreateValidator(
+			valAddrStr,
+			valPubKeys[i],
+			sdk.NewCoin(cfg.BondDenom, cfg.BondedTokens),
+			stakingtypes.NewDescription(nodeDirName, "", "", "", ""),
+			stakingtypes.NewCommissionRates(commission, sdkmath.LegacyOneDec(), sdkmath.LegacyOneDec()),
+			sdkmath.OneInt(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		p2pURL, err := url.Parse(v.P2PAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		memo := fmt.Sprintf("%s@%s:%s", nodeIDs[i], p2pURL.Hostname(), p2pURL.Port())
+		fee := sdk.NewCoins(sdk.NewCoin(fmt.Sprintf("%stoken", nodeDirName), sdkmath.NewInt(0)))
+		txBuilder := cfg.TxConfig.NewTxBuilder()
+		err = txBuilder.SetMsgs(createValMsg)
+		if err != nil {
+			return nil, err
+		}
+		txBuilder.SetFeeAmount(fee)    // Arbitrary fee
+		txBuilder.SetGasLimit(1000000) // Need at least 100386
+		txBuilder.SetMemo(memo)
+
+		txFactory := tx.Factory{}
+		txFactory = txFactory.
+			WithChainID(cfg.ChainID).
+			WithMemo(memo).
+			WithKeybase(kb).
+			WithTxConfig(cfg.TxConfig)
+
+		err = tx.Sign(context.Background(), txFactory, nodeDirName, txBuilder, true)
+		if err != nil {
+			return nil, err
+		}
+
+		txBz, err := cfg.TxConfig.TxJSONEncoder()(txBuilder.GetTx())
+		if err != nil {
+			return nil, err
+		}
+		err = writeFile(fmt.Sprintf("%v.json", nodeDirName), gentxsDir, txBz)
+		if err != nil {
+			return nil, err
+		}
+
+		clientCtx := client.Context{}.
+			WithKeyringDir(clientDir).
+			WithKeyring(kb).
+			WithHomeDir(cmtCfg.RootDir).
+			WithChainID(cfg.ChainID).
+			WithInterfaceRegistry(cfg.InterfaceRegistry).
+			WithCodec(cfg.Codec).
+			WithLegacyAmino(cfg.LegacyAmino).
+			WithTxConfig(cfg.TxConfig).
+			WithAccountRetriever(cfg.AccountRetriever)
+
+		v.ClientCtx = clientCtx
+		v.NodeID = nodeID
+		v.PubKey = pubKey
+		v.Address = addr
+		v.ValAddress = sdk.ValAddress(addr)
+		v.partition = network.partition
+		network.Validators[i] = v
+	}
+
+	err := initGenFiles(cfg, genAccounts, genBalances, genFiles)
+	if err != nil {
+		return nil, err
+	}
+	err = collectGenFiles(cfg, network.Validators, network.BaseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < cfg.NumFullNodes; i++ {
+		v, err := network.newFullNode(cfg.NumValidators + i)
+		if err != nil {
+			return nil, err
+		}
+		network.FullNodes = append(network.FullNodes, v)
+	}
+
+	if cfg.Topology != nil {
+		nodes := network.nodes()
+		for i := range cfg.Topology {
+			if i < 0 || i >= len(nodes) {
+				return nil, fmt.Errorf("node %d of the topology out of range, the network has %d nodes", i, len(nodes))
+			}
+		}
+
+		for i, v := range nodes {
+			if err := network.setPersistentPeers(v, cfg.Topology[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	l.Log("starting test network...")
+	height, err := network.start()
+	if err != nil {
+		// stop the nodes started so far, and release the ports and databases of every node
+		network.Cleanup()
+		return nil, err
+	}
+
+	l.Log("started test network at height:", height)
+
+	// Ensure we cleanup incase any test was abruptly halted (e.g. SIGINT) as any
+	// defer in a test would not be called.
+	trapSignal(network)
+
+	return network, nil
+}
+
+// start starts the validators and the full nodes of the network, and waits for the first block.
+func (n *Network) start() (int64, error) {
+	for idx, v := range n.Validators {
+		if err := startInProcess(n.Config, v); err != nil {
+			return 0, err
+		}
+		n.Logger.Log("started validator", idx)
+	}
+
+	for idx, v := range n.FullNodes {
+		if err := startInProcess(n.Config, v); err != nil {
+			return 0, err
+		}
+		n.Logger.Log("started full node", idx)
+	}
+
+	return n.WaitForHeightWithTimeout(1, startupTimeout)
+}
+
+// newNode returns the i-th node of the network, whose servers listen on their own ports. Its dirs and app config
+// are written under the base dir.
+func newNode(cfg Config, baseDir string, i int) (_ *Validator, err error) {
+	// the ports of the node are reserved until it starts
+	var ports portReservation
+	defer func() {
+		if err != nil {
+			ports.release()
+		}
+	}()
+
+	appCfg := srvconfig.DefaultConfig()
+	appCfg.Pruning = cfg.PruningStrategy
+	appCfg.MinGasPrices = cfg.MinGasPrices
+	appCfg.API.Enable = true
+	appCfg.API.Swagger = false
+	appCfg.Telemetry.Enabled = false
+	appCfg.StateSync.SnapshotInterval = cfg.SnapshotInterval
+	appCfg.StateSync.SnapshotKeepRecent = cfg.SnapshotKeepRecent
+
+	ctx := server.NewDefaultContext()
+	cmtCfg := ctx.Config
+	cmtCfg.Consensus.TimeoutCommit = cfg.TimeoutCommit
+
+	// The configured listen addresses only apply to the first validator, all other nodes
+	// listen on free ports.
+	apiListenAddr, err := ports.listenAddr(i, cfg.APIAddress, "tcp://0.0.0.0:%s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get port for API server: %w", err)
+	}
+
+	appCfg.API.Address = apiListenAddr
+	apiURL, err := url.Parse(apiListenAddr)
+	if err != nil {
+		return nil, err
+	}
+	apiAddr := fmt.Sprintf("http://%s:%s", apiURL.Hostname(), apiURL.Port())
+
+	cmtCfg.RPC.ListenAddress, err = ports.listenAddr(i, cfg.RPCAddress, "tcp://0.0.0.0:%s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get port for RPC server: %w", err)
+	}
+
+	appCfg.GRPC.Address, err = ports.listenAddr(i, cfg.GRPCAddress, "0.0.0.0:%s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get port for GRPC server: %w", err)
+	}
+	appCfg.GRPC.Enable = true
+	appCfg.GRPCWeb.Enable = true
+
+	logger := log.NewNopLogger()
+	if cfg.EnableLogging {
+		logger = log.NewLogger(os.Stdout)
+	}
+
+	ctx.Logger = logger
+
+	nodeDirName := fmt.Sprintf("node%d", i)
+	nodeDir := filepath.Join(baseDir, nodeDirName, "simd")
+	clientDir := filepath.Join(baseDir, nodeDirName, "simcli")
+
+	err = os.MkdirAll(filepath.Join(nodeDir, "config"), 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(clientDir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	cmtCfg.SetRoot(nodeDir)
+	cmtCfg.Moniker = nodeDirName
+
+	cmtCfg.ProxyApp, err = ports.listenAddr(i, "", "tcp://0.0.0.0:%s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get port for Proxy server: %w", err)
+	}
+
+	p2pAddr, err := ports.listenAddr(i, "", "tcp://0.0.0.0:%s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get port for P2P server: %w", err)
+	}
+	cmtCfg.P2P.ListenAddress = p2pAddr
+	cmtCfg.P2P.AddrBookStrict = false
+	cmtCfg.P2P.AllowDuplicateIP = true
+	// nodes query their app for the peers they accept, so that the network can be partitioned
+	cmtCfg.FilterPeers = true
+
+	srvconfig.WriteConfigFile(filepath.Join(nodeDir, "config", "app.toml"), appCfg)
+
+	// Provide ChainID here since we can't modify it in the Comet config.
+	ctx.Viper.Set(flags.FlagChainID, cfg.ChainID)
+	// Provide the home dir for apps writing to it, e.g. the upgrade module when the chain halts for an upgrade.
+	ctx.Viper.Set(flags.FlagHome, cmtCfg.RootDir)
+
+	return &Validator{
+		AppConfig:   appCfg,
+		Ctx:         ctx,
+		Dir:         filepath.Join(baseDir, nodeDirName),
+		Moniker:     nodeDirName,
+		RPCAddress:  cmtCfg.RPC.ListenAddress,
+		P2PAddress:  cmtCfg.P2P.ListenAddress,
+		APIAddress:  apiAddr,
+		GRPCAddress: appCfg.GRPC.Address,
+		dbsMu:       &sync.Mutex{},
+		ports:       ports,
+	}, nil
+}
+
+// checkSDKConfig checks that the address codecs of the network use the bech32 prefixes of the process-wide SDK
+// config. The network encodes the addresses of its genesis with its own codecs, but the SDK modules still encode
+// addresses with the process-wide config, so networks running in the same test binary must share its prefixes.
+// Only the account and validator prefixes are checked, once, so that later changes to the config go unnoticed.
+func checkSDKConfig(cfg Config) error {
+	sdkConfig := sdk.GetConfig()
+	signingCtx := cfg.TxConfig.SigningContext()
+
+	for _, c := range []struct {
+		codec  address.Codec
+		prefix string
+	}{
+		{signingCtx.AddressCodec(), sdkConfig.GetBech32AccountAddrPrefix()},
+		{signingCtx.ValidatorAddressCodec(), sdkConfig.GetBech32ValidatorAddrPrefix()},
+	} {
+		addr, err := c.codec.BytesToString(make([]byte, 20))
+		if err != nil {
+			return err
+		}
+
+		prefix, _, err := bech32.DecodeAndConvert(addr)
+		if err != nil {
+			return err
+		}
+
+		if prefix != c.prefix {
+			return fmt.Errorf("the network encodes addresses with the bech32 prefix %q but the process-wide SDK "+
+				"config, which the SDK modules encode addresses with, uses %q", prefix, c.prefix)
+		}
+	}
+
+	return nil
+}
+
+// trapSignal traps SIGINT and SIGTERM, which clean up the running networks, including the given network, and
+// call os.Exit. The signals are trapped once for all the networks of the test binary, so that a network does not
+// exit the process before the other networks are cleaned up.
+func trapSignal(n *Network) {
+	running.Store(n, struct{}{})
+
+	trapOnce.Do(func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+		go func() {
+			sig := <-sigs
+
+			running.Range(func(n, _ any) bool {
+				n.(*Network).Cleanup()
+				return true
+			})
+			exitCode := 128
+
+			switch sig {
+			case syscall.SIGINT:
+				exitCode += int(syscall.SIGINT)
+			case syscall.SIGTERM:
+				exitCode += int(syscall.SIGTERM)
+			}
+
+			os.Exit(exitCode)
+		}()
+	})
+}
+
+// LatestHeight returns the latest height of the network or an error if the
+// query fails or no validator is running.
+func (n *Network) LatestHeight() (int64, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	timeout := time.NewTimer(time.Second * 5)
+	defer timeout.Stop()
+
+	val, err := n.runningValidator()
+	if err != nil {
+		return 0, err
+	}
+
+	var latestHeight int64
+	queryClient := cmtservice.NewServiceClient(val.ClientCtx)
+
+	for {
+		select {
+		case <-timeout.C:
+			return latestHeight, errors.New("timeout exceeded waiting for block")
+		case <-ticker.C:
+			done := make(chan struct{})
+			go func() {
+				res, err := queryClient.GetLatestBlock(context.Background(), &cmtservice.GetLatestBlockRequest{})
+				if err == nil && res != nil {
+					latestHeight = res.SdkBlock.Header.Height
+				}
+				done <- struct{}{}
+			}()
+			select {
+			case <-timeout.C:
+				return latestHeight, errors.New("timeout exceeded waiting for block")
+			case <-done:
+				if latestHeight != 0 {
+					return latestHeight, nil
+				}
+			}
+		}
+	}
+}
+
+// WaitForHeight performs a blocking check where it waits for a block to be
+// committed after a given block. If that height is not reached within a timeout,
+// an error is returned. Regardless, the latest height queried is returned.
+func (n *Network) WaitForHeight(h int64) (int64, error) {
+	return n.WaitForHeightWithTimeout(h, 10*time.Second)
+}
+
+// WaitForHeightWithTimeout is the same as WaitForHeight except the caller can
+// provide a custom timeout.
+func (n *Network) WaitForHeightWithTimeout(h int64, t time.Duration) (int64, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	timeout := time.NewTimer(t)
+	defer timeout.Stop()
+
+	val, err := n.runningValidator()
+	if err != nil {
+		return 0, err
+	}
+
+	var latestHeight int64
+	queryClient := cmtservice.NewServiceClient(val.ClientCtx)
+
+	for {
+		select {
+		case <-timeout.C:
+			return latestHeight, errors.New("timeout exceeded waiting for block")
+		case <-ticker.C:
+
+			res, err := queryClient.GetLatestBlock(context.Background(), &cmtservice.GetLatestBlockRequest{})
+			if err == nil && res != nil {
+				latestHeight = res.GetSdkBlock().Header.Height
+				if latestHeight >= h {
+					return latestHeight, nil
+				}
+			}
+		}
+	}
+}
+
+// RetryForBlocks will wait for the next block and execute the function provided.
+// It will do this until the function returns a nil error or until the number of
+// blocks has been reached.
+func (n *Network) RetryForBlocks(retryFunc func() error, blocks int) error {
+	for i := 0; i < blocks; i++ {
+		_ = n.WaitForNextBlock()
+		err := retryFunc()
+		if err == nil {
+			return nil
+		}
+		// we've reached the last block to wait, return the error
+		if i == blocks-1 {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitForNextBlock waits for the next block to be committed, returning an error
+// upon failure.
+func (n *Network) WaitForNextBlock() error {
+	lastBlock, err := n.LatestHeight()
+	if err != nil {
+		return err
+	}
+
+	_, err = n.WaitForHeight(lastBlock + 1)
+	return err
+}
+
+// Cleanup removes the root testing (temporary) directory and stops both the
+// CometBFT and API services. This method must be called when a test is
+// finished, typically in a defer.
+func (n *Network) Cleanup() {
+	running.Delete(n)
+
+	n.Logger.Log("cleaning up test network...")
+
+	for _, v := range n.nodes() {
+		n.stopValidator(v)
+		v.closeDBs()
+		v.ports.release()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if n.Config.CleanupDir {
+		_ = os.RemoveAll(n.BaseDir)
+	}
+
+	n.Logger.Log("finished cleaning up test network")
+}
+
+// nodes returns the validators and the full nodes of the network.
+func (n *Network) nodes() []*Validator {
+	nodes := make([]*Validator, 0, len(n.Validators)+len(n.FullNodes))
+	return append(append(nodes, n.Validators...), n.FullNodes...)
+}
+
+// stopValidator stops the gRPC and API servers, the CometBFT node and the application of the validator.
+func (n *Network) stopValidator(v *Validator) {
+	// cancel the validator's context which will signal to the gRPC and API
+	// goroutines that they should gracefully exit. They are not started if the node failed to start.
+	if v.cancelFn != nil {
+		v.cancelFn()
+
+		if err := v.errGroup.Wait(); err != nil {
+			n.Logger.Log("unexpected error waiting for validator gRPC and API processes to exit", "err", err)
+		}
+	}
+
+	if v.tmNode != nil && v.tmNode.IsRunning() {
+		if err := v.tmNode.Stop(); err != nil {
+			n.Logger.Log("failed to stop validator CometBFT node", "err", err)
+		}
+	}
+
+	if v.app != nil {
+		if err := v.app.Close(); err != nil {
+			n.Logger.Log("failed to stop validator ABCI application", "err", err)
+		}
+		v.app = nil
+	}
+}
+
+// closeDBs closes the databases of the stopped node of the validator, which are opened again by its next start.
+// The databases are closed once the calls in progress return, and the routines of the stopped node that still
+// run find them empty, see nodeDB.
+func (v *Validator) closeDBs() {
+	v.dbsMu.Lock()
+	defer v.dbsMu.Unlock()
+
+	for _, db := range v.dbs {
+		_ = db.closeDB()
+	}
+
+	v.dbs = nil
+}
+
+// printMnemonic prints a provided mnemonic seed phrase on a network logger
+// for debugging and manual testing
+func printMnemonic(l Logger, secret string) {
+	lines := []string{
+		"THIS MNEMONIC IS FOR TESTING PURPOSES ONLY",
+		"DO NOT USE IN PRODUCTION",
+		"",
+		strings.Join(strings.Fields(secret)[0:8], " "),
+		strings.Join(strings.Fields(secret)[8:16], " "),
+		strings.Join(strings.Fields(secret)[16:24], " "),
+	}
+
+	maxLineLength := 0
+	for _, line := range lines {
+		if len(line) > maxLineLength {
+			maxLineLength = len(line)
+		}
+	}
+
+	l.Log("\n")
+	l.Log(strings.Repeat("+", maxLineLength+8))
+	for _, line := range lines {
+		l.Logf("++  %s  ++\n", centerText(line, maxLineLength))
+	}
+	l.Log(strings.Repeat("+", maxLineLength+8))
+	l.Log("\n")
+}
+
+// centerText centers text across a fixed width, filling either side with whitespace buffers
+func centerText(text string, width int) string {
+	textLen := len(text)
+	leftBuffer := strings.Repeat(" ", (width-textLen)/2)
+	rightBuffer := strings.Repeat(" ", (width-textLen)/2+(width-textLen)%2)
+
+	return fmt.Sprintf("%s%s%s", leftBuffer, text, rightBuffer)
+}
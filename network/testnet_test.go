@@ -0,0 +1,63 @@
+package network_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	sdknetwork "github.com/cosmos/cosmos-sdk/testutil/network"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	bankcli "github.com/cosmos/cosmos-sdk/x/bank/client/cli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/account"
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestConcurrentNetworks(t *testing.T) {
+	var (
+		mu sync.Mutex
+		// addresses maps the listen addresses of the nodes to the chain ID of their network
+		addresses = make(map[string]string)
+	)
+
+	// the group returns once both parallel networks are done
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			t.Run(fmt.Sprintf("network %d", i), func(t *testing.T) {
+				t.Parallel()
+
+				cfg := network.NewConfig(sdknetwork.MinimumAppConfig())
+				cfg.NumValidators = 2
+				s := network.NewSuite(t, cfg)
+
+				mu.Lock()
+				for _, v := range s.Network.Validators {
+					for _, addr := range []string{v.RPCAddress, v.P2PAddress, v.APIAddress, v.GRPCAddress} {
+						chainID, taken := addresses[addr]
+						require.False(t, taken, "%s is also used by %s", addr, chainID)
+						addresses[addr] = cfg.ChainID
+					}
+				}
+				mu.Unlock()
+
+				recipient := account.NewAccount()
+				amount := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10))
+				for _, node := range s.Nodes() {
+					out, err := node.RunCLI(bankcli.NewSendTxCmd(cfg.TxConfig.SigningContext().AddressCodec()),
+						node.Validator().Address.String(), recipient.Address().String(), amount.String())
+					require.NoError(t, err)
+					require.NoError(t, out.TxResult.Err())
+				}
+
+				// the first validator may not have committed the block including the tx of the last one yet
+				require.NoError(t, s.Network.WaitForNextBlock())
+				balances, err := s.Balances(*recipient)
+				require.NoError(t, err)
+				require.Equal(t, sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 20)), balances)
+			})
+		}
+	})
+
+	require.Len(t, addresses, 2*2*4)
+}
@@ -0,0 +1,153 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	errorsmod "cosmossdk.io/errors"
+	abci "github.com/cometbft/cometbft/abci/types"
+	cmtbytes "github.com/cometbft/cometbft/libs/bytes"
+	coretypes "github.com/cometbft/cometbft/rpc/core/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/gogoproto/proto"
+)
+
+// txPollInterval is the interval at which the node is queried while waiting for a tx to be included.
+const txPollInterval = 200 * time.Millisecond
+
+// TxResult is the result of a tx, decoded with the network's codec.
+type TxResult struct {
+	Hash cmtbytes.HexBytes
+	// Height is the height of the block the tx was committed in, or 0 if the result is the result of CheckTx.
+	Height int64
+
+	Code      uint32
+	Codespace string
+	Log       string
+	GasWanted int64
+	GasUsed   int64
+
+	// MsgResponses are the responses of the messages of the tx, in the order of the messages, unpacked into
+	// their proto message. They are empty if the tx failed or was not executed yet.
+	MsgResponses []proto.Message
+	// Events are the ABCI events emitted by the tx.
+	Events []abci.Event
+	// TypedEvents are the events that were emitted as typed SDK events, decoded into their proto message.
+	TypedEvents []proto.Message
+}
+
+// IsOK returns whether the tx succeeded.
+func (r *TxResult) IsOK() bool {
+	return r.Code == 0
+}
+
+// Err returns the error of the tx, or nil if it succeeded. The error wraps the error registered for the code
+// and codespace of the result, so that it can be matched with errors.Is, e.g.
+//
+//	errors.Is(res.Err(), sdkerrors.ErrInsufficientFunds)
+func (r *TxResult) Err() error {
+	if r.IsOK() {
+		return nil
+	}
+
+	return errorsmod.ABCIError(r.Codespace, r.Code, r.Log)
+}
+
+// DecodeBroadcastTx decodes the result of a tx broadcast in sync or async mode. Txs broadcast in async mode
+// have an empty result, as the result of CheckTx is not awaited.
+func (s *TestSuite) DecodeBroadcastTx(res *coretypes.ResultBroadcastTx) (*TxResult, error) {
+	return s.decodeTxResult(res.Hash, 0, &abci.ExecTxResult{
+		Code:      res.Code,
+		Data:      res.Data,
+		Log:       res.Log,
+		Codespace: res.Codespace,
+	})
+}
+
+// DecodeBroadcastTxCommit decodes the result of a tx broadcast in commit mode. The result is the result of
+// CheckTx if the tx failed its check, and the result of its execution otherwise.
+func (s *TestSuite) DecodeBroadcastTxCommit(res *coretypes.ResultBroadcastTxCommit) (*TxResult, error) {
+	if res.CheckTx.Code != 0 {
+		return s.decodeTxResult(res.Hash, 0, &abci.ExecTxResult{
+			Code:      res.CheckTx.Code,
+			Data:      res.CheckTx.Data,
+			Log:       res.CheckTx.Log,
+			GasWanted: res.CheckTx.GasWanted,
+			GasUsed:   res.CheckTx.GasUsed,
+			Events:    res.CheckTx.Events,
+			Codespace: res.CheckTx.Codespace,
+		})
+	}
+
+	return s.decodeTxResult(res.Hash, res.Height, &res.TxResult)
+}
+
+// DecodeBlockTx decodes the result of a tx included in a block returned by Block.
+func (s *TestSuite) DecodeBlockTx(height int64, tx BlockTx) (*TxResult, error) {
+	return s.decodeTxResult(tx.Hash, height, tx.Result)
+}
+
+// WaitForTx waits until the tx with the given hash is included in a block by the targeted node and returns its
+// result, or returns an error once ctx is done.
+func (s *TestSuite) WaitForTx(ctx context.Context, hash []byte) (*TxResult, error) {
+	cometClient, err := s.GetCometClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(txPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// the tx is not found until it is included
+		if res, err := cometClient.Tx(ctx, hash, false); err == nil {
+			return s.decodeTxResult(res.Hash, res.Height, &res.TxResult)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("tx %X not included: %w", hash, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *TestSuite) decodeTxResult(hash cmtbytes.HexBytes, height int64, res *abci.ExecTxResult) (*TxResult, error) {
+	result := &TxResult{
+		Hash:        hash,
+		Height:      height,
+		Code:        res.Code,
+		Codespace:   res.Codespace,
+		Log:         res.Log,
+		GasWanted:   res.GasWanted,
+		GasUsed:     res.GasUsed,
+		Events:      res.Events,
+		TypedEvents: typedEvents(res.Events),
+	}
+
+	if !result.IsOK() || len(res.Data) == 0 {
+		return result, nil
+	}
+
+	var msgData sdk.TxMsgData
+	if err := proto.Unmarshal(res.Data, &msgData); err != nil {
+		return nil, fmt.Errorf("decoding msg responses of tx %s: %w", hash, err)
+	}
+
+	registry := s.Network.Config.InterfaceRegistry
+	for _, msgAny := range msgData.MsgResponses {
+		msg, err := registry.Resolve(msgAny.TypeUrl)
+		if err != nil {
+			return nil, fmt.Errorf("decoding msg responses of tx %s: %w", hash, err)
+		}
+
+		if err := proto.Unmarshal(msgAny.Value, msg); err != nil {
+			return nil, fmt.Errorf("decoding msg responses of tx %s: %w", hash, err)
+		}
+
+		result.MsgResponses = append(result.MsgResponses, msg)
+	}
+
+	return result, nil
+}
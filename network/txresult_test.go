@@ -0,0 +1,101 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govv1 "github.com/cosmos/cosmos-sdk/x/gov/types/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestTxResultErr(t *testing.T) {
+	t.Run("nil for successful txs", func(t *testing.T) {
+		res := network.TxResult{}
+		require.True(t, res.IsOK())
+		require.NoError(t, res.Err())
+	})
+
+	t.Run("matches registered errors", func(t *testing.T) {
+		res := network.TxResult{
+			Code:      sdkerrors.ErrInsufficientFunds.ABCICode(),
+			Codespace: sdkerrors.ErrInsufficientFunds.Codespace(),
+			Log:       "spendable balance 0stake is smaller than 1stake",
+		}
+		require.False(t, res.IsOK())
+		require.ErrorIs(t, res.Err(), sdkerrors.ErrInsufficientFunds)
+		require.NotErrorIs(t, res.Err(), sdkerrors.ErrInsufficientFee)
+		require.ErrorContains(t, res.Err(), res.Log)
+	})
+
+	t.Run("unregistered errors", func(t *testing.T) {
+		res := network.TxResult{Code: 1000, Codespace: "unknown"}
+		require.Error(t, res.Err())
+		require.NotErrorIs(t, res.Err(), sdkerrors.ErrInsufficientFunds)
+	})
+}
+
+func TestDecodeTxResult(t *testing.T) {
+	ctx := context.Background()
+	coins := sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 1_000_000_000))
+
+	cfg := network.NewConfig(fullAppConfig(), network.GovVotingPeriod(time.Minute))
+	s := network.NewSuite(t, cfg, network.WithFundedAccounts(2, coins))
+	granter, grantee := s.Accounts[0], s.Accounts[1]
+
+	params, err := s.GovParams()
+	require.NoError(t, err)
+
+	// the gov msg has a non-empty response and the authz msg emits a typed event
+	submit, err := govv1.NewMsgSubmitProposal(nil, params.MinDeposit, granter.Address().String(), "metadata", "title", "summary", false)
+	require.NoError(t, err)
+	grant, err := authz.NewMsgGrant(granter.Address(), grantee.Address(),
+		authz.NewGenericAuthorization(sdk.MsgTypeURL(&banktypes.MsgSend{})), nil)
+	require.NoError(t, err)
+
+	bz, err := s.CreateTxBytes(ctx, network.TxGenInfo{
+		Account:  granter,
+		GasLimit: 1_000_000,
+		Fee:      sdk.NewCoins(sdk.NewInt64Coin(sdk.DefaultBondDenom, 10)),
+	}, submit, grant)
+	require.NoError(t, err)
+
+	// the result of CheckTx does not hold the msg responses
+	res, err := s.BroadcastTx(ctx, bz, network.BroadcastModeSync)
+	require.NoError(t, err)
+	checkRes, err := s.DecodeBroadcastTx(res)
+	require.NoError(t, err)
+	require.NoError(t, checkRes.Err())
+	require.Zero(t, checkRes.Height)
+	require.Empty(t, checkRes.MsgResponses)
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	txRes, err := s.WaitForTx(waitCtx, res.Hash)
+	require.NoError(t, err)
+	require.NoError(t, txRes.Err())
+	require.Equal(t, res.Hash, txRes.Hash)
+	require.NotZero(t, txRes.Height)
+
+	require.Len(t, txRes.MsgResponses, 2)
+	require.Equal(t, &govv1.MsgSubmitProposalResponse{ProposalId: 1}, txRes.MsgResponses[0])
+	require.Equal(t, &authz.MsgGrantResponse{}, txRes.MsgResponses[1])
+
+	var grantEvents []*authz.EventGrant
+	for _, event := range txRes.TypedEvents {
+		if grantEvent, ok := event.(*authz.EventGrant); ok {
+			grantEvents = append(grantEvents, grantEvent)
+		}
+	}
+	require.Equal(t, []*authz.EventGrant{{
+		MsgTypeUrl: sdk.MsgTypeURL(&banktypes.MsgSend{}),
+		Granter:    granter.Address().String(),
+		Grantee:    grantee.Address().String(),
+	}}, grantEvents)
+}
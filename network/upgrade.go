@@ -0,0 +1,155 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	abci "github.com/cometbft/cometbft/abci/types"
+	"github.com/cosmos/cosmos-sdk/testutil/network"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+)
+
+// upgradePollInterval is the interval at which the nodes are checked while waiting for the chain to halt.
+const upgradePollInterval = 200 * time.Millisecond
+
+// Upgrade describes a software upgrade of the network, run with TestSuite.Upgrade.
+type Upgrade struct {
+	// Name is the name of the upgrade plan, which must be handled by the app built by AppConstructor.
+	Name string
+	// Height is the height at which the chain halts for the upgrade. It must leave enough blocks for the upgrade
+	// proposal to pass.
+	Height int64
+	// Info is the info of the upgrade plan.
+	Info string
+	// AppConstructor builds the app of the new binary, e.g. with NewAppConstructor, WithUpgradeHandler and
+	// WithOnDiskDB. The network must have been started with an app storing its state in the validators' data
+	// dirs, e.g. built with WithOnDiskDB.
+	AppConstructor network.AppConstructor
+
+	// PreUpgrade is called once the upgrade proposal passed, before the chain halts. Optional.
+	PreUpgrade func(s *TestSuite) error
+	// PostUpgrade is called once the upgraded network committed a block with the new app. Optional.
+	PostUpgrade func(s *TestSuite) error
+}
+
+// Upgrade runs a software upgrade of the network: it passes a MsgSoftwareUpgrade proposal for the upgrade with
+// SubmitAndPassProposal, waits for the chain to halt at the upgrade height, then restarts every validator from
+// its home dir with the app of the new binary. The gov voting period must be short enough for the proposal to
+// pass before the upgrade height.
+func (s *TestSuite) Upgrade(ctx context.Context, upgrade Upgrade) error {
+	if upgrade.AppConstructor == nil {
+		return fmt.Errorf("no app constructor for upgrade %s", upgrade.Name)
+	}
+
+	if err := requireOnDiskState(s.Network.Validators[0].app, "upgrade "+upgrade.Name); err != nil {
+		return err
+	}
+
+	msg := &upgradetypes.MsgSoftwareUpgrade{
+		Authority: authtypes.NewModuleAddress(govtypes.ModuleName).String(),
+		Plan: upgradetypes.Plan{
+			Name:   upgrade.Name,
+			Height: upgrade.Height,
+			Info:   upgrade.Info,
+		},
+	}
+
+	if _, err := s.SubmitAndPassProposal(ctx, msg); err != nil {
+		return fmt.Errorf("upgrade proposal: %w", err)
+	}
+
+	if upgrade.PreUpgrade != nil {
+		if err := upgrade.PreUpgrade(s); err != nil {
+			return fmt.Errorf("pre-upgrade hook: %w", err)
+		}
+	}
+
+	if err := s.waitForUpgradeHalt(ctx, upgrade.Height); err != nil {
+		return err
+	}
+
+	for i := range s.Network.Validators {
+		if err := s.Network.StopValidator(i); err != nil {
+			return err
+		}
+	}
+
+	s.Network.Config.AppConstructor = upgrade.AppConstructor
+
+	// the new app executes the upgrade when replaying the block at the upgrade height
+	for i, node := range s.Nodes() {
+		if err := node.StartNode(); err != nil {
+			return fmt.Errorf("restarting validator %d with the upgraded app: %w", i, err)
+		}
+	}
+
+	if err := s.waitForHeight(ctx, upgrade.Height+1); err != nil {
+		return err
+	}
+
+	if upgrade.PostUpgrade != nil {
+		if err := upgrade.PostUpgrade(s); err != nil {
+			return fmt.Errorf("post-upgrade hook: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// waitForUpgradeHalt waits until every validator has received the block at the upgrade height, which the app
+// fails to execute as it has no handler for the upgrade.
+func (s *TestSuite) waitForUpgradeHalt(ctx context.Context, height int64) error {
+	ticker := time.NewTicker(upgradePollInterval)
+	defer ticker.Stop()
+
+	for {
+		halted := true
+		for i, v := range s.Network.Validators {
+			// the app is queried through the connection of the node, which serializes the calls to the app
+			info, err := v.tmNode.ProxyApp().Query().Info(ctx, &abci.RequestInfo{})
+			if err != nil {
+				return err
+			}
+
+			if info.LastBlockHeight >= height {
+				return fmt.Errorf("validator %d executed the block at upgrade height %d instead of halting", i, height)
+			}
+
+			if v.tmNode.BlockStore().Height() < height {
+				halted = false
+			}
+		}
+
+		if halted {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for the chain to halt at upgrade height %d: %w", height, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForHeight waits until the network has committed the block at the given height.
+func (s *TestSuite) waitForHeight(ctx context.Context, height int64) error {
+	ticker := time.NewTicker(upgradePollInterval)
+	defer ticker.Stop()
+
+	for {
+		latest, err := s.Network.LatestHeight()
+		if err == nil && latest >= height {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for height %d: %w", height, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
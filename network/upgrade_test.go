@@ -0,0 +1,94 @@
+package network_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	upgradetypes "cosmossdk.io/x/upgrade/types"
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	servertypes "github.com/cosmos/cosmos-sdk/server/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/module"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skip-mev/chaintestutil/network"
+)
+
+func TestUpgrade(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	appConfig := fullAppConfig()
+	cfg := network.NewConfig(appConfig, network.GovVotingPeriod(5*time.Second))
+	cfg.NumValidators = 2
+	cfg.AppConstructor = network.NewAppConstructor(appConfig, "", network.WithOnDiskDB())
+	s := network.NewSuite(t, cfg)
+
+	// the handler of the upgrade disables sends of the frozen denom, as a gov proposal would
+	frozen := &banktypes.MsgSetSendEnabled{
+		Authority:   authtypes.NewModuleAddress(govtypes.ModuleName).String(),
+		SendEnabled: []*banktypes.SendEnabled{{Denom: "frozen", Enabled: false}},
+	}
+	upgradedApp := func(val network.ValidatorI) servertypes.Application {
+		// the handler executes the msg with the router of the app of the validator
+		var app *baseapp.BaseApp
+		return network.NewAppConstructor(appConfig, "",
+			network.WithOnDiskDB(),
+			network.WithBaseAppOptions(func(bapp *baseapp.BaseApp) { app = bapp }),
+			network.WithUpgradeHandler("v2", func(ctx context.Context, _ upgradetypes.Plan, fromVM module.VersionMap) (module.VersionMap, error) {
+				_, err := app.MsgServiceRouter().Handler(frozen)(sdk.UnwrapSDKContext(ctx), frozen)
+				return fromVM, err
+			}),
+		)(val)
+	}
+
+	height, err := s.Network.LatestHeight()
+	require.NoError(t, err)
+	upgradeHeight := height + 15
+
+	var preUpgrade bool
+	err = s.Upgrade(ctx, network.Upgrade{
+		Name:           "v2",
+		Height:         upgradeHeight,
+		AppConstructor: upgradedApp,
+		PreUpgrade: func(s *network.TestSuite) error {
+			preUpgrade = true
+
+			plan, err := s.UpgradePlan()
+			require.NoError(t, err)
+			require.NotNil(t, plan)
+			require.Equal(t, "v2", plan.Name)
+			require.Equal(t, upgradeHeight, plan.Height)
+			return nil
+		},
+		PostUpgrade: func(s *network.TestSuite) error {
+			conn, err := s.GRPC()
+			if err != nil {
+				return err
+			}
+
+			res, err := banktypes.NewQueryClient(conn).SendEnabled(ctx, &banktypes.QuerySendEnabledRequest{Denoms: []string{"frozen"}})
+			if err != nil {
+				return err
+			}
+			require.Equal(t, frozen.SendEnabled, res.SendEnabled)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, preUpgrade, "pre-upgrade hook was not called")
+
+	for i, node := range s.Nodes() {
+		require.Eventually(t, func() bool {
+			return nodeHeight(t, node) > upgradeHeight
+		}, time.Minute, 100*time.Millisecond, "validator %d did not commit blocks after the upgrade", i)
+	}
+
+	plan, err := s.UpgradePlan()
+	require.NoError(t, err)
+	require.Nil(t, plan)
+}
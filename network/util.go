@@ -0,0 +1,442 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"cosmossdk.io/log"
+	dbm "github.com/cometbft/cometbft-db"
+	cmtcfg "github.com/cometbft/cometbft/config"
+	"github.com/cometbft/cometbft/node"
+	"github.com/cometbft/cometbft/p2p"
+	pvm "github.com/cometbft/cometbft/privval"
+	"github.com/cometbft/cometbft/proxy"
+	"github.com/cometbft/cometbft/rpc/client/local"
+	sm "github.com/cometbft/cometbft/state"
+	cmttypes "github.com/cometbft/cometbft/types"
+	cmttime "github.com/cometbft/cometbft/types/time"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/server/api"
+	servergrpc "github.com/cosmos/cosmos-sdk/server/grpc"
+	servercmtlog "github.com/cosmos/cosmos-sdk/server/log"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/cosmos/cosmos-sdk/x/genutil"
+	genutiltypes "github.com/cosmos/cosmos-sdk/x/genutil/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// portRangeStart and portRangeSize bound the ports of the nodes, below the ephemeral port range of Linux so that
+// they do not collide with the ports picked by the OS for outgoing connections.
+const (
+	portRangeStart = 20000
+	portRangeSize  = 12000
+)
+
+// nextPort is the offset in the port range of the last port handed out. It is shared by all the networks of the
+// test binary, so that networks running concurrently never get the same port, and starts at an offset derived
+// from the process ID so that the test binaries of different packages run in parallel by go test probe distinct
+// ports.
+var nextPort atomic.Uint32
+
+func init() {
+	nextPort.Store(uint32(os.Getpid()*7919) % portRangeSize)
+}
+
+// portReservation holds listeners on the ports of a node until its servers bind them, so that the ports are not
+// handed out to other processes probing for free ports in between.
+type portReservation []net.Listener
+
+// listenAddr returns the listen address of the i-th node for a server, formatted from a free port which is
+// reserved until released. The configured address is only used for the first node, as every node needs its own
+// port.
+func (r *portReservation) listenAddr(i int, configured, format string) (string, error) {
+	if i == 0 && configured != "" {
+		return configured, nil
+	}
+
+	l, err := freePort()
+	if err != nil {
+		return "", err
+	}
+	*r = append(*r, l)
+
+	return fmt.Sprintf(format, strconv.Itoa(l.Addr().(*net.TCPAddr).Port)), nil
+}
+
+// release closes the listeners reserving the ports, right before the servers of the node bind them.
+func (r *portReservation) release() {
+	for _, l := range *r {
+		_ = l.Close()
+	}
+	*r = nil
+}
+
+// freePort returns a listener on a port of the range that is free on the host. Ports are handed out in turn, so
+// that a port is only handed out again once the whole range was, and is skipped if a server still listens on it.
+func freePort() (net.Listener, error) {
+	for i := 0; i < portRangeSize; i++ {
+		port := portRangeStart + nextPort.Add(1)%portRangeSize
+
+		l, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", port))
+		if err != nil {
+			continue
+		}
+
+		return l, nil
+	}
+
+	return nil, errors.New("no free port left in the port range")
+}
+
+func startInProcess(cfg Config, val *Validator) error {
+	// the reserved ports are released right before the node starts, or if it fails to
+	defer val.ports.release()
+
+	logger := val.Ctx.Logger
+	cmtCfg := val.Ctx.Config
+	cmtCfg.Instrumentation.Prometheus = false
+
+	if err := val.AppConfig.ValidateBasic(); err != nil {
+		return err
+	}
+
+	nodeKey, err := p2p.LoadOrGenNodeKey(cmtCfg.NodeKeyFile())
+	if err != nil {
+		return err
+	}
+
+	app := cfg.AppConstructor(*val)
+	val.app = app
+
+	appGenesisProvider := func() (*cmttypes.GenesisDoc, error) {
+		appGenesis, err := genutiltypes.AppGenesisFromFile(cmtCfg.GenesisFile())
+		if err != nil {
+			return nil, err
+		}
+
+		return appGenesis.ToGenesisDoc()
+	}
+
+	cmtApp := server.NewCometABCIWrapper(app)
+	if val.partition != nil {
+		cmtApp = val.partition.wrap(val.NodeID, cmtApp)
+	}
+	if cfg.ABCIRecorder != nil {
+		cmtApp = cfg.ABCIRecorder.wrap(val.Moniker, cmtApp)
+	}
+	tmNode, err := node.NewNode( //resleak:notresource
+		cmtCfg,
+		pvm.LoadOrGenFilePV(cmtCfg.PrivValidatorKeyFile(), cmtCfg.PrivValidatorStateFile()),
+		nodeKey,
+		proxy.NewLocalClientCreator(cmtApp),
+		appGenesisProvider,
+		val.dbProvider,
+		node.DefaultMetricsProvider(cmtCfg.Instrumentation),
+		servercmtlog.CometLoggerWrapper{Logger: logger.With("module", val.Moniker)},
+	)
+	if err != nil {
+		return err
+	}
+
+	// the node and the servers of the app bind their ports from here on
+	val.ports.release()
+	if err := tmNode.Start(); err != nil {
+		return err
+	}
+	val.tmNode = tmNode
+
+	val.RPCClient = local.New(tmNode)
+	val.ClientCtx = val.ClientCtx.
+		WithClient(val.RPCClient)
+
+	app.RegisterTxService(val.ClientCtx)
+	app.RegisterTendermintService(val.ClientCtx)
+	app.RegisterNodeService(val.ClientCtx, *val.AppConfig)
+
+	ctx := context.Background()
+	ctx, val.cancelFn = context.WithCancel(ctx)
+	val.errGroup, ctx = errgroup.WithContext(ctx)
+
+	grpcCfg := val.AppConfig.GRPC
+
+	grpcSrv, err := servergrpc.NewGRPCServer(val.ClientCtx, app, grpcCfg)
+	if err != nil {
+		return err
+	}
+
+	// Start the gRPC server in a goroutine. Note, the provided ctx will ensure
+	// that the server is gracefully shut down.
+	val.errGroup.Go(func() error {
+		return servergrpc.StartGRPCServer(ctx, logger.With(log.ModuleKey, "grpc-server"), grpcCfg, grpcSrv)
+	})
+
+	val.grpc = grpcSrv
+
+	apiSrv := api.New(val.ClientCtx, logger.With(log.ModuleKey, "api-server"), val.grpc)
+	app.RegisterAPIRoutes(apiSrv, val.AppConfig.API)
+
+	val.errGroup.Go(func() error {
+		return apiSrv.Start(ctx, *val.AppConfig)
+	})
+
+	val.api = apiSrv
+
+	return nil
+}
+
+// dbProvider opens the databases of the validator's node. The databases are kept open when the node stops, as
+// routines of the stopped node may still read from them, and reused when it is restarted. They are closed by
+// closeDBs, e.g. when the network is cleaned up.
+func (v *Validator) dbProvider(ctx *cmtcfg.DBContext) (dbm.DB, error) {
+	v.dbsMu.Lock()
+	defer v.dbsMu.Unlock()
+
+	if v.dbs == nil {
+		v.dbs = make(map[string]*nodeDB)
+	}
+
+	db, ok := v.dbs[ctx.ID]
+	if !ok {
+		backend, err := cmtcfg.DefaultDBProvider(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		db = &nodeDB{DB: backend}
+		v.dbs[ctx.ID] = db
+	}
+
+	return db, nil
+}
+
+// stateStore returns the state store of the validator's node, which holds the responses of its app to the
+// FinalizeBlock calls.
+func (v *Validator) stateStore() (sm.Store, error) {
+	v.dbsMu.Lock()
+	db, ok := v.dbs["state"]
+	v.dbsMu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("the state database of %s is not open", v.Moniker)
+	}
+
+	return sm.NewStore(db, sm.StoreOptions{}), nil
+}
+
+// nodeDB is a database of a node, which is not closed by the node using it. CometBFT does not wait for the
+// routines of its reactors to exit when the node stops, and they may still read from the databases of the node
+// for a few gossip intervals. Once closed with closeDB, the database behaves as an empty database for these
+// routines, which treat missing data as not found, instead of failing on a closed database.
+type nodeDB struct {
+	dbm.DB
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// closeDB closes the database, waiting for the calls in progress to return.
+func (db *nodeDB) closeDB() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+
+	return db.DB.Close()
+}
+
+func (db *nodeDB) Get(key []byte) ([]byte, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, nil
+	}
+
+	return db.DB.Get(key)
+}
+
+func (db *nodeDB) Has(key []byte) (bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return false, nil
+	}
+
+	return db.DB.Has(key)
+}
+
+func (db *nodeDB) Set(key, value []byte) error {
+	return db.write(func() error { return db.DB.Set(key, value) })
+}
+
+func (db *nodeDB) SetSync(key, value []byte) error {
+	return db.write(func() error { return db.DB.SetSync(key, value) })
+}
+
+func (db *nodeDB) Delete(key []byte) error {
+	return db.write(func() error { return db.DB.Delete(key) })
+}
+
+func (db *nodeDB) DeleteSync(key []byte) error {
+	return db.write(func() error { return db.DB.DeleteSync(key) })
+}
+
+func (db *nodeDB) Iterator(start, end []byte) (dbm.Iterator, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return dbm.NewMemDB().Iterator(start, end)
+	}
+
+	return db.DB.Iterator(start, end)
+}
+
+func (db *nodeDB) ReverseIterator(start, end []byte) (dbm.Iterator, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return dbm.NewMemDB().ReverseIterator(start, end)
+	}
+
+	return db.DB.ReverseIterator(start, end)
+}
+
+// NewBatch returns a batch of the database, whose writes are discarded if the database is closed when it is
+// written.
+func (db *nodeDB) NewBatch() dbm.Batch {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return dbm.NewMemDB().NewBatch()
+	}
+
+	return db.DB.NewBatch()
+}
+
+// Close is a no-op, as the node using the database does not own it, see closeDB.
+func (db *nodeDB) Close() error {
+	return nil
+}
+
+// write runs the write unless the database is closed, in which case it is discarded.
+func (db *nodeDB) write(fn func() error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil
+	}
+
+	return fn()
+}
+
+func collectGenFiles(cfg Config, vals []*Validator, outputDir string) error {
+	genTime := cmttime.Now()
+
+	for i := 0; i < cfg.NumValidators; i++ {
+		cmtCfg := vals[i].Ctx.Config
+
+		nodeDir := filepath.Join(outputDir, vals[i].Moniker, "simd")
+		gentxsDir := filepath.Join(outputDir, "gentxs")
+
+		cmtCfg.Moniker = vals[i].Moniker
+		cmtCfg.SetRoot(nodeDir)
+
+		initCfg := genutiltypes.NewInitConfig(cfg.ChainID, gentxsDir, vals[i].NodeID, vals[i].PubKey)
+
+		genFile := cmtCfg.GenesisFile()
+		appGenesis, err := genutiltypes.AppGenesisFromFile(genFile)
+		if err != nil {
+			return err
+		}
+
+		appState, err := genutil.GenAppStateFromConfig(cfg.Codec, cfg.TxConfig,
+			cmtCfg, initCfg, appGenesis, banktypes.GenesisBalancesIterator{}, genutiltypes.DefaultMessageValidator, cfg.TxConfig.SigningContext().ValidatorAddressCodec())
+		if err != nil {
+			return err
+		}
+
+		// overwrite each validator's genesis file to have a canonical genesis time
+		appGenesis = genutiltypes.NewAppGenesisWithVersion(cfg.ChainID, appState)
+		appGenesis.GenesisTime = genTime
+		appGenesis.Consensus.Params = cfg.ConsensusParams
+		if err := appGenesis.ValidateAndComplete(); err != nil {
+			return err
+		}
+
+		if err := appGenesis.SaveAs(genFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func initGenFiles(cfg Config, genAccounts []authtypes.GenesisAccount, genBalances []banktypes.Balance, genFiles []string) error {
+	// set the accounts in the genesis state
+	var authGenState authtypes.GenesisState
+	cfg.Codec.MustUnmarshalJSON(cfg.GenesisState[authtypes.ModuleName], &authGenState)
+
+	accounts, err := authtypes.PackAccounts(genAccounts)
+	if err != nil {
+		return err
+	}
+
+	authGenState.Accounts = append(authGenState.Accounts, accounts...)
+	cfg.GenesisState[authtypes.ModuleName] = cfg.Codec.MustMarshalJSON(&authGenState)
+
+	// set the balances in the genesis state
+	var bankGenState banktypes.GenesisState
+	cfg.Codec.MustUnmarshalJSON(cfg.GenesisState[banktypes.ModuleName], &bankGenState)
+
+	bankGenState.Balances = append(bankGenState.Balances, genBalances...)
+	cfg.GenesisState[banktypes.ModuleName] = cfg.Codec.MustMarshalJSON(&bankGenState)
+
+	appGenStateJSON, err := json.MarshalIndent(cfg.GenesisState, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	appGenesis := genutiltypes.AppGenesis{
+		ChainID:  cfg.ChainID,
+		AppState: appGenStateJSON,
+		Consensus: &genutiltypes.ConsensusGenesis{
+			Validators: nil,
+		},
+	}
+
+	// generate empty genesis files for each validator and save
+	for i := 0; i < cfg.NumValidators; i++ {
+		if err := appGenesis.SaveAs(genFiles[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFile(name, dir string, contents []byte) error {
+	file := filepath.Join(dir, name)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("could not create directory %q: %w", dir, err)
+	}
+
+	return os.WriteFile(file, contents, 0o600)
+}
@@ -2,8 +2,11 @@
 package sample
 
 import (
+	"fmt"
 	"math/rand"
+	"os"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -207,7 +210,45 @@ func ZeroTime() time.Time {
 	return time.UnixMilli(0).UTC()
 }
 
-// Rand returns a sample Rand object for randomness
+// SeedEnv is the environment variable setting the seed returned by Seed, e.g. to reproduce a failed run.
+const SeedEnv = "CHAINTESTUTIL_SEED"
+
+// Seed returns the seed set by the SeedEnv environment variable, or a seed drawn from the current time if it is
+// not set.
+func Seed() int64 {
+	value, ok := os.LookupEnv(SeedEnv)
+	if !ok {
+		return time.Now().UnixNano()
+	}
+
+	seed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("invalid %s: %s", SeedEnv, err))
+	}
+
+	return seed
+}
+
+var (
+	sourceMu sync.Mutex
+	// source is seeded once with Seed, so that successive Rand calls return different streams which are
+	// reproducible with SeedEnv.
+	source *rand.Rand
+)
+
+// Rand returns a sample Rand object for randomness, seeded from a source seeded once with Seed
 func Rand() *rand.Rand {
-	return rand.New(rand.NewSource(time.Now().Unix()))
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+
+	if source == nil {
+		source = RandWithSeed(Seed())
+	}
+
+	return RandWithSeed(source.Int63())
+}
+
+// RandWithSeed returns a sample Rand object for randomness, seeded with the given seed
+func RandWithSeed(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
 }